@@ -0,0 +1,316 @@
+package ecs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type ArchetypeId uint32
+
+// Archetype 原型表：组件集合完全相同的实体存放在同一张表中，
+// 每种组件占据一列，同一行的各列属于同一个实体
+type Archetype struct {
+	id        ArchetypeId
+	signature []ComponentId
+	index     map[ComponentId]int
+	entities  []IEntity
+	columns   [][]IComponent
+
+	addEdges    map[ComponentId]*Archetype
+	removeEdges map[ComponentId]*Archetype
+}
+
+func newArchetype(id ArchetypeId, signature []ComponentId) *Archetype {
+	a := &Archetype{
+		id:          id,
+		signature:   signature,
+		index:       make(map[ComponentId]int, len(signature)),
+		entities:    make([]IEntity, 0),
+		columns:     make([][]IComponent, len(signature)),
+		addEdges:    make(map[ComponentId]*Archetype),
+		removeEdges: make(map[ComponentId]*Archetype),
+	}
+	for i, componentId := range signature {
+		a.index[componentId] = i
+		a.columns[i] = make([]IComponent, 0)
+	}
+	return a
+}
+
+func (a *Archetype) ID() ArchetypeId {
+	return a.id
+}
+
+// Signature 原型包含的组件ID（升序）
+func (a *Archetype) Signature() []ComponentId {
+	return a.signature
+}
+
+func (a *Archetype) Len() int {
+	return len(a.entities)
+}
+
+func (a *Archetype) Entities() []IEntity {
+	return a.entities
+}
+
+func (a *Archetype) Has(componentId ComponentId) bool {
+	_, ok := a.index[componentId]
+	return ok
+}
+
+// Column 获取指定组件的列，行号与 Entities 一一对应
+func (a *Archetype) Column(componentId ComponentId) ([]IComponent, bool) {
+	i, ok := a.index[componentId]
+	if !ok {
+		return nil, false
+	}
+	return a.columns[i], true
+}
+
+func (a *Archetype) pushRow(entity IEntity) int {
+	a.entities = append(a.entities, entity)
+	for i := range a.columns {
+		a.columns[i] = append(a.columns[i], nil)
+	}
+	return len(a.entities) - 1
+}
+
+// swapRemove 删除指定行，并返回被移动到该行的实体（如果有）
+func (a *Archetype) swapRemove(row int) (moved IEntity) {
+	last := len(a.entities) - 1
+	if row != last {
+		a.entities[row] = a.entities[last]
+		for i := range a.columns {
+			a.columns[i][row] = a.columns[i][last]
+		}
+		moved = a.entities[row]
+	}
+
+	a.entities[last] = nil
+	a.entities = a.entities[:last]
+	for i := range a.columns {
+		a.columns[i][last] = nil
+		a.columns[i] = a.columns[i][:last]
+	}
+	return moved
+}
+
+type entityLocation struct {
+	archetype *Archetype
+	row       int
+}
+
+type archetypeMatch struct {
+	archetypes []*Archetype
+	checked    int
+}
+
+// Archetypes 管理所有原型表以及实体所在的位置
+type Archetypes struct {
+	empty      *Archetype
+	list       []*Archetype
+	byKey      map[string]*Archetype
+	locations  map[EntityId]entityLocation
+	matchCache map[string]*archetypeMatch
+	matchMu    sync.Mutex
+}
+
+func NewArchetypes() *Archetypes {
+	a := &Archetypes{
+		list:       make([]*Archetype, 0),
+		byKey:      make(map[string]*Archetype),
+		locations:  make(map[EntityId]entityLocation),
+		matchCache: make(map[string]*archetypeMatch),
+	}
+	a.empty = a.getOrCreate(make([]ComponentId, 0))
+	return a
+}
+
+func (a *Archetypes) List() []*Archetype {
+	return a.list
+}
+
+func (a *Archetypes) getOrCreate(signature []ComponentId) *Archetype {
+	key := signatureKey(signature)
+	if archetype, ok := a.byKey[key]; ok {
+		return archetype
+	}
+
+	archetype := newArchetype(ArchetypeId(len(a.list)), signature)
+	a.list = append(a.list, archetype)
+	a.byKey[key] = archetype
+	return archetype
+}
+
+func (a *Archetypes) withComponent(from *Archetype, componentId ComponentId) *Archetype {
+	if to, ok := from.addEdges[componentId]; ok {
+		return to
+	}
+
+	signature := make([]ComponentId, 0, len(from.signature)+1)
+	signature = append(signature, from.signature...)
+	signature = append(signature, componentId)
+	sortComponentIds(signature)
+
+	to := a.getOrCreate(signature)
+	from.addEdges[componentId] = to
+	to.removeEdges[componentId] = from
+	return to
+}
+
+func (a *Archetypes) withoutComponent(from *Archetype, componentId ComponentId) *Archetype {
+	if to, ok := from.removeEdges[componentId]; ok {
+		return to
+	}
+
+	signature := make([]ComponentId, 0, len(from.signature))
+	for _, id := range from.signature {
+		if id != componentId {
+			signature = append(signature, id)
+		}
+	}
+
+	to := a.getOrCreate(signature)
+	from.removeEdges[componentId] = to
+	to.addEdges[componentId] = from
+	return to
+}
+
+// Add 将实体放入空原型（已存在则忽略）
+func (a *Archetypes) Add(entity IEntity) {
+	if _, ok := a.locations[EntityId(entity.ID())]; ok {
+		return
+	}
+	row := a.empty.pushRow(entity)
+	a.locations[EntityId(entity.ID())] = entityLocation{archetype: a.empty, row: row}
+}
+
+// Remove 将实体从其所在的原型中移除
+func (a *Archetypes) Remove(entity IEntity) {
+	loc, ok := a.locations[EntityId(entity.ID())]
+	if !ok {
+		return
+	}
+	a.removeRow(loc)
+	delete(a.locations, EntityId(entity.ID()))
+}
+
+func (a *Archetypes) Get(entity IEntity, componentId ComponentId) (IComponent, bool) {
+	loc, ok := a.locations[EntityId(entity.ID())]
+	if !ok {
+		return nil, false
+	}
+	column, ok := loc.archetype.Column(componentId)
+	if !ok {
+		return nil, false
+	}
+	return column[loc.row], true
+}
+
+// Insert 设置实体的组件，必要时将实体迁移到新的原型
+func (a *Archetypes) Insert(entity IEntity, componentId ComponentId, component IComponent) {
+	a.Add(entity)
+	loc := a.locations[EntityId(entity.ID())]
+
+	if i, ok := loc.archetype.index[componentId]; ok {
+		loc.archetype.columns[i][loc.row] = component
+		return
+	}
+
+	to := a.withComponent(loc.archetype, componentId)
+	row := a.move(entity, loc, to)
+	to.columns[to.index[componentId]][row] = component
+}
+
+// Delete 删除实体的组件，并将实体迁移到对应的原型
+func (a *Archetypes) Delete(entity IEntity, componentId ComponentId) {
+	loc, ok := a.locations[EntityId(entity.ID())]
+	if !ok || !loc.archetype.Has(componentId) {
+		return
+	}
+
+	to := a.withoutComponent(loc.archetype, componentId)
+	a.move(entity, loc, to)
+}
+
+// Range 遍历实体的所有组件
+func (a *Archetypes) Range(entity IEntity, fn func(componentId ComponentId, component IComponent)) {
+	loc, ok := a.locations[EntityId(entity.ID())]
+	if !ok {
+		return
+	}
+	for i, componentId := range loc.archetype.signature {
+		fn(componentId, loc.archetype.columns[i][loc.row])
+	}
+}
+
+// Match 返回包含所有指定组件的原型
+func (a *Archetypes) Match(componentIds []ComponentId) []*Archetype {
+	f := &Filter{with: componentIds}
+	f.buildKey()
+	return a.MatchFilter(f)
+}
+
+// MatchFilter 返回满足过滤条件的原型，结果按过滤条件缓存，只对新增的原型做增量匹配
+func (a *Archetypes) MatchFilter(f *Filter) []*Archetype {
+	a.matchMu.Lock()
+	defer a.matchMu.Unlock()
+
+	match, ok := a.matchCache[f.key]
+	if !ok {
+		match = &archetypeMatch{archetypes: make([]*Archetype, 0)}
+		a.matchCache[f.key] = match
+	}
+
+	for ; match.checked < len(a.list); match.checked++ {
+		archetype := a.list[match.checked]
+		if f.matchArchetype(archetype) {
+			match.archetypes = append(match.archetypes, archetype)
+		}
+	}
+	return match.archetypes
+}
+
+func (a *Archetypes) move(entity IEntity, loc entityLocation, to *Archetype) int {
+	from := loc.archetype
+	row := to.pushRow(entity)
+	for i, componentId := range from.signature {
+		if j, ok := to.index[componentId]; ok {
+			to.columns[j][row] = from.columns[i][loc.row]
+		}
+	}
+
+	a.removeRow(loc)
+	a.locations[EntityId(entity.ID())] = entityLocation{archetype: to, row: row}
+	return row
+}
+
+func (a *Archetypes) removeRow(loc entityLocation) {
+	if moved := loc.archetype.swapRemove(loc.row); moved != nil {
+		a.locations[EntityId(moved.ID())] = entityLocation{archetype: loc.archetype, row: loc.row}
+	}
+}
+
+func sortComponentIds(componentIds []ComponentId) {
+	sort.Slice(componentIds, func(i, j int) bool {
+		return componentIds[i] < componentIds[j]
+	})
+}
+
+func signatureKey(componentIds []ComponentId) string {
+	sorted := make([]ComponentId, len(componentIds))
+	copy(sorted, componentIds)
+	sortComponentIds(sorted)
+
+	var sb strings.Builder
+	for i, componentId := range sorted {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(componentId), 10))
+	}
+	return sb.String()
+}
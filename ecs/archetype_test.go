@@ -0,0 +1,78 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testPosition struct {
+	Component
+	X, Y float64
+}
+
+type testVelocity struct {
+	Component
+	X, Y float64
+}
+
+func TestArchetype_QueryAndMove(t *testing.T) {
+	w := NewWorld(WithStorageMode(StorageArchetype))
+
+	entities := make([]IEntity, 0)
+	for i := 0; i < 4; i++ {
+		pos := SpawnComponent[*testPosition](w)
+		pos.X = float64(i)
+		entities = append(entities, SpawnEmptyEntity(w, pos))
+	}
+
+	entities[1].AddComponents(SpawnComponent[*testVelocity](w))
+	entities[3].AddComponents(SpawnComponent[*testVelocity](w))
+
+	if n := len(w.GetQuery().Query(&testPosition{})); n != 4 {
+		t.Fatalf("expected 4 entities with position, got %d", n)
+	}
+	if n := len(w.GetQuery().Query(&testPosition{}, &testVelocity{})); n != 2 {
+		t.Fatalf("expected 2 entities with position and velocity, got %d", n)
+	}
+
+	// 被交换到前面的行仍然能取到自己的组件
+	for i, entity := range entities {
+		if pos := GetComponent[*testPosition](entity); pos == nil || pos.X != float64(i) {
+			t.Fatalf("entity %d lost its position component", i)
+		}
+	}
+
+	entities[1].RemoveComponents(&testVelocity{})
+	if n := len(w.GetQuery().Query(&testVelocity{})); n != 1 {
+		t.Fatalf("expected 1 entity with velocity after remove, got %d", n)
+	}
+
+	w.GetCommands().DestroyEntity(entities[0]).Execute()
+	if n := len(w.GetQuery().Query(&testPosition{})); n != 3 {
+		t.Fatalf("expected 3 entities after destroy, got %d", n)
+	}
+	if pos := GetComponent[*testPosition](entities[2]); pos == nil || pos.X != 2 {
+		t.Fatalf("entity 2 lost its position component after destroy")
+	}
+}
+
+func TestArchetype_RangeArchetypes(t *testing.T) {
+	w := NewWorld(WithStorageMode(StorageArchetype))
+	for i := 0; i < 3; i++ {
+		SpawnEmptyEntity(w, SpawnComponent[*testPosition](w), SpawnComponent[*testVelocity](w))
+	}
+
+	posId := ComponentId(w.GetCompId(reflect.TypeOf(&testPosition{})))
+	rows := 0
+	w.GetQuery().RangeArchetypes(func(archetype *Archetype) {
+		column, ok := archetype.Column(posId)
+		if !ok {
+			t.Fatalf("matched archetype has no position column")
+		}
+		rows += len(column)
+	}, &testPosition{})
+
+	if rows != 3 {
+		t.Fatalf("expected 3 rows, got %d", rows)
+	}
+}
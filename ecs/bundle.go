@@ -0,0 +1,139 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// IBundle 组件组合，可以和组件一样传给 SpawnEmptyEntity、AddComponents、RemoveComponents 和 Commands
+type IBundle interface {
+	IComponent
+	bundle()
+}
+
+// Bundle 嵌入到结构体中表示组件组合，结构体中类型为组件指针的导出字段会被展开，
+// 类型为组合的字段会被递归展开；添加时为 nil 的字段从组件池中创建
+//
+//	type PhysicsBundle struct {
+//		ecs.Bundle
+//		Position *Position
+//		Velocity *Velocity
+//	}
+type Bundle struct {
+	Component
+}
+
+func (b *Bundle) bundle() {}
+
+type bundleField struct {
+	index  int
+	t      reflect.Type
+	nested bool
+}
+
+// bundleFields 组合类型的字段信息只解析一次
+var bundleFields sync.Map
+
+var (
+	componentInterface = reflect.TypeOf((*IComponent)(nil)).Elem()
+	bundleInterface    = reflect.TypeOf((*IBundle)(nil)).Elem()
+)
+
+func bundleFieldsOf(t reflect.Type) []bundleField {
+	if fields, ok := bundleFields.Load(t); ok {
+		return fields.([]bundleField)
+	}
+
+	fields := make([]bundleField, 0)
+	elem := t.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		if !field.IsExported() || field.Anonymous || field.Type.Kind() != reflect.Ptr {
+			continue
+		}
+		switch {
+		case field.Type.Implements(bundleInterface):
+			fields = append(fields, bundleField{index: i, t: field.Type, nested: true})
+		case field.Type.Implements(componentInterface):
+			fields = append(fields, bundleField{index: i, t: field.Type})
+		}
+	}
+	bundleFields.Store(t, fields)
+	return fields
+}
+
+// rangeBundle 按字段顺序遍历组合中的组件，未设置的字段 component 为 nil
+func rangeBundle(bundle IBundle, fn func(t reflect.Type, component IComponent)) {
+	v := reflect.ValueOf(bundle)
+	if v.IsNil() {
+		v = reflect.New(v.Type().Elem())
+	}
+	for _, field := range bundleFieldsOf(v.Type()) {
+		value := v.Elem().Field(field.index)
+		if field.nested {
+			nested := value
+			if nested.IsNil() {
+				nested = reflect.New(field.t.Elem())
+			}
+			rangeBundle(nested.Interface().(IBundle), fn)
+			continue
+		}
+		if value.IsNil() {
+			fn(field.t, nil)
+		} else {
+			fn(field.t, value.Interface().(IComponent))
+		}
+	}
+}
+
+// expandBundles 将组件列表中的组合展开为组件，组合中为 nil 的字段从组件池中创建
+func expandBundles(w IWorld, components []IComponent) []IComponent {
+	hasBundle := false
+	for _, component := range components {
+		if _, ok := component.(IBundle); ok {
+			hasBundle = true
+			break
+		}
+	}
+	if !hasBundle {
+		return components
+	}
+
+	expanded := make([]IComponent, 0, len(components))
+	for _, component := range components {
+		bundle, ok := component.(IBundle)
+		if !ok {
+			expanded = append(expanded, component)
+			continue
+		}
+		rangeBundle(bundle, func(t reflect.Type, component IComponent) {
+			if component == nil {
+				component = w.GetComponentMap()[internalsOf(w).ensureComponent(t)].CreateComponent()
+			}
+			expanded = append(expanded, component)
+		})
+	}
+	return expanded
+}
+
+// componentTypes 组件列表中所有组件的类型，组合会被展开
+func componentTypes(components []IComponent) []reflect.Type {
+	types := make([]reflect.Type, 0, len(components))
+	for _, component := range components {
+		bundle, ok := component.(IBundle)
+		if !ok {
+			types = append(types, reflect.TypeOf(component))
+			continue
+		}
+		rangeBundle(bundle, func(t reflect.Type, component IComponent) {
+			types = append(types, t)
+		})
+	}
+	return types
+}
+
+// RemoveBundle 移除组合中的所有组件
+func RemoveBundle[T IBundle](entity IEntity) {
+	var bundle T
+	entity.RemoveComponents(bundle)
+}
@@ -0,0 +1,82 @@
+package ecs
+
+import "testing"
+
+type testMoverBundle struct {
+	Bundle
+	Position *testPosition
+	Velocity *testVelocity
+}
+
+type testUnitBundle struct {
+	Bundle
+	Mover  *testMoverBundle
+	Health *testHealth
+}
+
+func TestBundle_Spawn(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			entity := SpawnEmptyEntity(w, &testUnitBundle{
+				Mover:  &testMoverBundle{Position: &testPosition{X: 1}},
+				Health: &testHealth{Value: 10},
+			})
+
+			if p := GetComponent[*testPosition](entity); p == nil || p.X != 1 {
+				t.Fatalf("nested bundle component should be added")
+			}
+			if GetComponent[*testVelocity](entity) == nil {
+				t.Fatalf("unset bundle field should be created from the pool")
+			}
+			if h := GetComponent[*testHealth](entity); h == nil || h.Value != 10 {
+				t.Fatalf("bundle component should be added")
+			}
+			if _, ok := w.GetRegistry().ComponentOf(typeOf[*testUnitBundle]()); ok {
+				t.Fatalf("bundle should not be registered as a component")
+			}
+
+			RemoveBundle[*testMoverBundle](entity)
+			if GetComponent[*testPosition](entity) != nil || GetComponent[*testVelocity](entity) != nil {
+				t.Fatalf("bundle components should be removed")
+			}
+			if GetComponent[*testHealth](entity) == nil {
+				t.Fatalf("components outside the bundle should be kept")
+			}
+		})
+	}
+}
+
+func TestBundle_Commands(t *testing.T) {
+	w := NewWorld()
+	commands := w.GetCommands()
+
+	entity := commands.Spawn(&testMoverBundle{Velocity: &testVelocity{X: 2}})
+	commands.Insert(entity, &testHealth{Value: 3})
+	commands.Execute()
+	if v := GetComponent[*testVelocity](entity); v == nil || v.X != 2 {
+		t.Fatalf("bundle should be spawned by commands")
+	}
+
+	commands.Remove(entity, (*testMoverBundle)(nil))
+	commands.Execute()
+	if GetComponent[*testPosition](entity) != nil || GetComponent[*testVelocity](entity) != nil {
+		t.Fatalf("bundle should be removed by commands")
+	}
+}
+
+func TestBundle_Prefab(t *testing.T) {
+	w := NewWorld()
+	prefab := NewPrefab("mover", &testMoverBundle{Position: &testPosition{X: 4}})
+
+	a := w.Instantiate(prefab)
+	b := w.Instantiate(prefab, &testPosition{X: 7})
+	if GetComponent[*testPosition](a).X != 4 || GetComponent[*testPosition](b).X != 7 {
+		t.Fatalf("bundle template should be cloned and overridable")
+	}
+	if GetComponent[*testPosition](a) == prefab.Components[0].(*testMoverBundle).Position {
+		t.Fatalf("bundle template should be copied")
+	}
+	if GetComponent[*testVelocity](a) == nil {
+		t.Fatalf("unset bundle field should be created")
+	}
+}
@@ -1,60 +1,115 @@
 package ecs
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
+
+// Command 延迟执行的命令，在同步点按加入的顺序依次执行
+type Command func(w *World)
 
 type Commands struct {
-	w *World
+	mu    sync.Mutex
+	w     *World
+	queue []Command
 }
 
 func NewCommands(w *World) *Commands {
 	return &Commands{
-		w: w,
+		w:     w,
+		queue: make([]Command, 0),
 	}
 }
 
-func (c *Commands) doSpawn(entity IEntity, components ...IComponent) {
-	if _, ok := c.w.entities[EntityId(entity.ID())]; !ok {
-		c.w.entities[EntityId(entity.ID())] = entity
-	}
+// Add 加入一个自定义命令
+func (c *Commands) Add(command Command) *Commands {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queue = append(c.queue, command)
+	return c
+}
 
-	if len(components) == 0 {
+func (c *Commands) doSpawn(entity IEntity, components ...IComponent) {
+	if !c.w.IsAlive(entity) {
 		return
 	}
 
-	component := components[0]
-	remains := components[1:]
+	c.w.registerEntity(entity)
 
-	// 设置组件的ID
-	component.SetID(c.w.GetCompId(reflect.TypeOf(component)))
-	componentId := ComponentId(component.ID())
+	for _, component := range c.w.withRequired(entity, expandBundles(c.w, components)) {
+		// 设置组件的ID，没有注册过的组件类型会被自动注册
+		componentId := c.w.ensureComponent(reflect.TypeOf(component))
+		component.SetID(uint64(componentId))
 
-	if _, ok := c.w.componentMap[componentId]; !ok {
-		return
+		// 建立实体和组件的映射关系
+		c.w.insertComponent(entity, componentId, component)
 	}
+}
 
-	// 根据组件和实体的映射关系
-	componentInfo := c.w.componentMap[componentId]
-	componentInfo.AddEntity(entity)
+// Spawn 立即分配实体ID并返回句柄，组件在命令执行时才会添加
+func (c *Commands) Spawn(components ...IComponent) IEntity {
+	entity := NewEntity(c.w)
+	c.Add(func(w *World) {
+		w.commands.doSpawn(entity, components...)
+	})
+	return entity
+}
 
-	// 建立实体和组件的映射关系
-	if _, ok := c.w.entities[EntityId(entity.ID())]; !ok {
-		c.w.entities[EntityId(entity.ID())] = entity
-	}
+// Insert 为实体添加组件，已存在的同类组件会被替换
+func (c *Commands) Insert(entity IEntity, components ...IComponent) *Commands {
+	return c.Add(func(w *World) {
+		entity.AddComponents(components...)
+	})
+}
 
-	c.w.entities[EntityId(entity.ID())].GetComponentContainer()[componentId] = component
-	c.doSpawn(entity, remains...)
+// Remove 移除实体的组件
+func (c *Commands) Remove(entity IEntity, components ...IComponent) *Commands {
+	return c.Add(func(w *World) {
+		entity.RemoveComponents(components...)
+	})
 }
 
 func (c *Commands) DestroyEntity(entity IEntity) *Commands {
-	c.w.destroyEntities = append(c.w.destroyEntities, entity)
-	return c
+	return c.Add(func(w *World) {
+		w.destroy(entity)
+	})
 }
 
+// Len 待执行的命令数量
+func (c *Commands) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.queue)
+}
+
+// Execute 按顺序执行所有待处理的命令，执行过程中新加入的命令也会在本次执行
 func (c *Commands) Execute() {
-	for _, entity := range c.w.destroyEntities {
-		c.w.destroy(entity)
+	for {
+		c.mu.Lock()
+		queue := c.queue
+		c.queue = make([]Command, 0)
+		c.mu.Unlock()
+
+		if len(queue) == 0 {
+			return
+		}
+		for _, command := range queue {
+			command(c.w)
+		}
 	}
-	c.w.destroyEntities = c.w.destroyEntities[:0]
+}
+
+// Append 将其他命令缓冲中的命令按顺序追加到当前缓冲，并清空被追加的缓冲
+func (c *Commands) Append(others ...*Commands) *Commands {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, other := range others {
+		other.mu.Lock()
+		c.queue = append(c.queue, other.queue...)
+		other.queue = make([]Command, 0)
+		other.mu.Unlock()
+	}
+	return c
 }
 
 func (c *Commands) SetResource(component IComponent) *Commands {
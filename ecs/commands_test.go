@@ -0,0 +1,114 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSpawnerSystem struct {
+	System
+	seenDuringUpdate int
+}
+
+func (s *testSpawnerSystem) Update() {
+	for _, entity := range s.Query.Query(&testPosition{}) {
+		s.Commands.Spawn(SpawnComponent[*testPosition](s.World))
+		s.Commands.Insert(entity, SpawnComponent[*testVelocity](s.World))
+	}
+	s.seenDuringUpdate = len(s.Query.Query(&testPosition{}))
+}
+
+type testCounterSystem struct {
+	System
+	count int
+}
+
+func (s *testCounterSystem) Update() {
+	s.count = len(s.Query.Query(&testPosition{}))
+}
+
+func TestCommands_DeferredUntilStageEnd(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			spawner := &testSpawnerSystem{System: *NewSystem(w)}
+			sameStage := &testCounterSystem{System: *NewSystem(w)}
+			nextStage := &testCounterSystem{System: *NewSystem(w)}
+			w.AddSystem(StagePreUpdate, spawner)
+			w.AddSystem(StagePreUpdate, sameStage)
+			w.AddUpdateSystem(nextStage)
+
+			SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			w.Update()
+
+			if spawner.seenDuringUpdate != 1 || sameStage.count != 1 {
+				t.Fatalf("commands should not apply within the stage, got %d / %d", spawner.seenDuringUpdate, sameStage.count)
+			}
+			if nextStage.count != 2 {
+				t.Fatalf("commands should apply before the next stage, got %d", nextStage.count)
+			}
+			if n := len(w.GetQuery().Query(&testVelocity{})); n != 1 {
+				t.Fatalf("expected inserted velocity, got %d", n)
+			}
+		})
+	}
+}
+
+func TestCommands_Order(t *testing.T) {
+	w := NewWorld()
+	cmds := w.GetCommands()
+
+	entity := cmds.Spawn(SpawnComponent[*testPosition](w))
+	cmds.Insert(entity, SpawnComponent[*testVelocity](w)).
+		Remove(entity, &testPosition{})
+	if w.GetQuery().Has(entity, &testVelocity{}) {
+		t.Fatalf("commands should be deferred")
+	}
+
+	cmds.Execute()
+	if !w.GetQuery().Has(entity, &testVelocity{}) || w.GetQuery().Has(entity, &testPosition{}) {
+		t.Fatalf("commands applied out of order")
+	}
+
+	cmds.DestroyEntity(entity).Execute()
+	if w.IsAlive(entity) {
+		t.Fatalf("entity should be destroyed")
+	}
+}
+
+type testLoggingSystem struct {
+	System
+	name   string
+	delay  time.Duration
+	arrive *sync.WaitGroup
+	log    *[]string
+}
+
+func (s *testLoggingSystem) Update() {
+	s.arrive.Done()
+	s.arrive.Wait()
+	time.Sleep(s.delay)
+	s.Commands.Add(func(w *World) {
+		*s.log = append(*s.log, s.name)
+	})
+}
+
+func TestCommands_PerSystemBuffers(t *testing.T) {
+	w := NewWorld(WithParallelism(2))
+	log := make([]string, 0)
+	arrive := &sync.WaitGroup{}
+
+	// a 在调度中排在前面，但比 b 晚写入命令
+	a := &testLoggingSystem{System: *NewSystem(w), name: "a", delay: 20 * time.Millisecond, arrive: arrive, log: &log}
+	a.Writes(&testPosition{})
+	b := &testLoggingSystem{System: *NewSystem(w), name: "b", arrive: arrive, log: &log}
+	b.Writes(&testVelocity{})
+	w.AddUpdateSystem(a)
+	w.AddUpdateSystem(b)
+
+	arrive.Add(2)
+	w.Update()
+	if len(log) != 2 || log[0] != "a" || log[1] != "b" {
+		t.Fatalf("commands should be applied in schedule order, got %v", log)
+	}
+}
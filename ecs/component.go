@@ -1,6 +1,10 @@
 package ecs
 
-import "github.com/INT-Game/go-ecs/sparse_set"
+import (
+	"reflect"
+
+	"github.com/INT-Game/go-ecs/sparse_set"
+)
 
 type ComponentContainer map[ComponentId]IComponent
 
@@ -23,27 +27,61 @@ type IComponentInfo interface {
 	CreateComponent() IComponent
 	DestroyComponent(elem IComponent)
 	Density() []uint64
+	Contains(entityId EntityId) bool
+	GetTicks(entityId EntityId) (ComponentTicks, bool)
+	SetAddedTick(entityId EntityId, tick uint64)
+	SetChangedTick(entityId EntityId, tick uint64)
+
+	getPool() IPool
 }
 
 type ComponentInfo[T IComponent] struct {
 	IComponentInfo
 	pool      *Pool[T]
 	sparseSet *sparse_set.SparseSet[uint64]
+	ticks     []ComponentTicks // 与 sparseSet 的 density 一一对应
 }
 
 func NewComponentInfo[T IComponent](w IWorld) *ComponentInfo[T] {
 	return &ComponentInfo[T]{
 		pool:      NewPool[T](w),
 		sparseSet: sparse_set.NewSparseSet[uint64](32),
+		ticks:     make([]ComponentTicks, 0),
 	}
 }
 
+// newComponentInfoOf 为只在运行时才知道类型的组件创建 ComponentInfo
+func newComponentInfoOf(w IWorld, t reflect.Type) *ComponentInfo[IComponent] {
+	return &ComponentInfo[IComponent]{
+		pool:      newPoolOf[IComponent](w, t),
+		sparseSet: sparse_set.NewSparseSet[uint64](32),
+		ticks:     make([]ComponentTicks, 0),
+	}
+}
+
+// 稀疏集中保存的是实体的槽位索引而不是完整的实体ID，索引会被复用，稀疏集的大小不会无限增长
+
 func (c *ComponentInfo[T]) AddEntity(e IEntity) {
-	c.sparseSet.Add(e.ID())
+	index := entityIndex(e)
+	if c.sparseSet.Contains(index) {
+		return
+	}
+	c.sparseSet.Add(index)
+	c.ticks = append(c.ticks, ComponentTicks{})
 }
 
 func (c *ComponentInfo[T]) RemoveEntity(e IEntity) {
-	c.sparseSet.Remove(e.ID())
+	index := entityIndex(e)
+	i, ok := c.sparseSet.Index(index)
+	if !ok {
+		return
+	}
+
+	// 稀疏集删除时会把最后一个元素交换到被删除的位置，tick 保持同样的交换
+	last := len(c.ticks) - 1
+	c.ticks[i] = c.ticks[last]
+	c.ticks = c.ticks[:last]
+	c.sparseSet.Remove(index)
 }
 
 func (c *ComponentInfo[T]) CreateComponent() IComponent {
@@ -54,10 +92,48 @@ func (c *ComponentInfo[T]) DestroyComponent(elem IComponent) {
 	c.pool.Destroy(elem)
 }
 
+// Density 拥有该组件的实体槽位索引，可以通过 EntityId.Index 对应到实体
 func (c *ComponentInfo[T]) Density() []uint64 {
 	return c.sparseSet.Density()
 }
 
+func (c *ComponentInfo[T]) Contains(entityId EntityId) bool {
+	return c.sparseSet.Contains(uint64(entityId.Index()))
+}
+
+func (c *ComponentInfo[T]) GetTicks(entityId EntityId) (ComponentTicks, bool) {
+	i, ok := c.sparseSet.Index(uint64(entityId.Index()))
+	if !ok {
+		return ComponentTicks{}, false
+	}
+	return c.ticks[i], true
+}
+
+func (c *ComponentInfo[T]) SetAddedTick(entityId EntityId, tick uint64) {
+	if i, ok := c.sparseSet.Index(uint64(entityId.Index())); ok {
+		c.ticks[i].Added = tick
+		c.ticks[i].Changed = tick
+	}
+}
+
+func (c *ComponentInfo[T]) SetChangedTick(entityId EntityId, tick uint64) {
+	if i, ok := c.sparseSet.Index(uint64(entityId.Index())); ok {
+		c.ticks[i].Changed = tick
+	}
+}
+
+// getPool 标签和值组件没有对象池，返回 nil
+func (c *ComponentInfo[T]) getPool() IPool {
+	if c.pool == nil {
+		return nil
+	}
+	return c.pool
+}
+
+func entityIndex(e IEntity) uint64 {
+	return uint64(EntityId(e.ID()).Index())
+}
+
 type IComponent interface {
 	Identifier
 	IdentifierSetter
@@ -71,8 +147,8 @@ type IComparableComponent interface {
 }
 
 type Component struct {
-	IComponent
-	id uint64
+	IComponent `json:"-"`
+	id         uint64
 }
 
 func (c *Component) SetID(id uint64) {
@@ -20,11 +20,19 @@ type Entity struct {
 }
 
 func NewEntity(w IWorld) *Entity {
-	return &Entity{
-		w:                  w,
-		id:                 w.IncrEntId(),
-		componentContainer: make(ComponentContainer),
+	return newEntityWithId(w, EntityId(w.AllocEntId()))
+}
+
+// newEntityWithId 使用已经分配好的实体ID创建实体
+func newEntityWithId(w IWorld, id EntityId) *Entity {
+	entity := &Entity{
+		w:  w,
+		id: uint64(id),
 	}
+	if w.GetStorageMode() == StorageSparseSet {
+		entity.componentContainer = make(ComponentContainer)
+	}
+	return entity
 }
 
 func (e *Entity) ID() uint64 {
@@ -35,53 +43,50 @@ func (e *Entity) GetEcsWorld() IWorld {
 	return e.w
 }
 
+// GetComponentContainer 获取实体的组件容器
+// 原型存储模式下组件保存在原型表中，这里返回的是按需拼装的只读副本
 func (e *Entity) GetComponentContainer() ComponentContainer {
+	if e.componentContainer == nil {
+		container := make(ComponentContainer)
+		internalsOf(e.w).rangeComponents(e, func(componentId ComponentId, component IComponent) {
+			container[componentId] = component
+		})
+		return container
+	}
 	return e.componentContainer
 }
 
 func (e *Entity) AddComponents(components ...IComponent) {
-	if _, ok := e.w.GetEntities()[EntityId(e.ID())]; !ok {
-		e.w.GetEntities()[EntityId(e.ID())] = e
+	if !e.w.IsAlive(e) {
+		return
 	}
 
-	for _, component := range components {
-		componentId := ComponentId(e.w.GetCompId(reflect.TypeOf(component)))
-		componentInfo, ok := e.w.GetComponentMap()[componentId]
-		if !ok {
-			return
-		}
+	w := internalsOf(e.w)
+	w.registerEntity(e)
 
-		if target, exists := e.componentContainer[componentId]; exists {
-			componentInfo.DestroyComponent(target)
-			delete(e.componentContainer, componentId)
-			componentInfo.RemoveEntity(e)
-		}
+	for _, component := range w.withRequired(e, expandBundles(w, components)) {
+		componentId := w.ensureComponent(reflect.TypeOf(component))
+		component.SetID(uint64(componentId))
 
-		e.componentContainer[componentId] = component
-		componentInfo.AddEntity(e)
+		w.insertComponent(e, componentId, component)
 	}
 }
 
 func (e *Entity) RemoveComponents(components ...IComponent) {
-	for _, component := range components {
-		componentId := ComponentId(e.w.GetCompId(reflect.TypeOf(component)))
-		componentInfo, ok := e.w.GetComponentMap()[componentId]
-		if !ok {
-			return
+	for _, t := range componentTypes(components) {
+		componentId := ComponentId(e.w.GetCompId(t))
+		if _, ok := e.w.GetComponentMap()[componentId]; !ok {
+			continue
 		}
 
-		if target, exists := e.componentContainer[componentId]; exists {
-			componentInfo.DestroyComponent(target)
-			delete(e.componentContainer, componentId)
-			componentInfo.RemoveEntity(e)
-		}
+		internalsOf(e.w).removeComponent(e, componentId)
 	}
 }
 
 func GetComponent[T IComponent](e IEntity) T {
 	t := reflect.TypeOf((*T)(nil)).Elem()
 	componentId := e.GetEcsWorld().GetCompId(t)
-	component, ok := e.GetComponentContainer()[ComponentId(componentId)]
+	component, ok := internalsOf(e.GetEcsWorld()).getComponent(e, ComponentId(componentId))
 	if !ok {
 		var zero T
 		return zero
@@ -0,0 +1,167 @@
+package ecs
+
+import "sync"
+
+const entityIndexBits = 32
+
+// NewEntityId 由索引和代数组成实体ID：低 32 位是索引，高 32 位是代数
+func NewEntityId(index uint32, generation uint32) EntityId {
+	return EntityId(uint64(generation)<<entityIndexBits | uint64(index))
+}
+
+// Index 实体在世界中的槽位索引，实体销毁后索引会被回收
+func (id EntityId) Index() uint32 {
+	return uint32(id)
+}
+
+// Generation 槽位被复用的次数，用于识别已经失效的实体句柄
+func (id EntityId) Generation() uint32 {
+	return uint32(id >> entityIndexBits)
+}
+
+type entitySlot struct {
+	generation uint32
+	alive      bool
+	entity     IEntity
+}
+
+// entityAllocator 分配实体ID，回收已销毁实体的索引
+// 并行的系统和 ParForEach 中的 Commands.Spawn 会在查询读取槽位的同时分配ID，所有读取都需要加读锁
+type entityAllocator struct {
+	mu       sync.RWMutex
+	slots    []entitySlot
+	freeList []uint32
+}
+
+func newEntityAllocator() *entityAllocator {
+	return &entityAllocator{
+		// 索引 0 保留，保证有效的实体ID不为 0
+		slots:    make([]entitySlot, 1),
+		freeList: make([]uint32, 0),
+	}
+}
+
+func (a *entityAllocator) alloc() EntityId {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n := len(a.freeList); n > 0 {
+		index := a.freeList[n-1]
+		a.freeList = a.freeList[:n-1]
+		a.slots[index].alive = true
+		return NewEntityId(index, a.slots[index].generation)
+	}
+
+	index := uint32(len(a.slots))
+	a.slots = append(a.slots, entitySlot{alive: true})
+	return NewEntityId(index, 0)
+}
+
+// free 回收索引，代数加一使旧的句柄失效
+func (a *entityAllocator) free(id EntityId) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.isAlive(id) {
+		return false
+	}
+	slot := &a.slots[id.Index()]
+	slot.alive = false
+	slot.generation++
+	slot.entity = nil
+	a.freeList = append(a.freeList, id.Index())
+	return true
+}
+
+// restore 将指定的实体ID标记为存活，用于加载快照时保留原来的实体ID，其余空闲的索引重新放入回收列表
+func (a *entityAllocator) restore(ids []EntityId) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, id := range ids {
+		index := int(id.Index())
+		for len(a.slots) <= index {
+			a.slots = append(a.slots, entitySlot{})
+		}
+		a.slots[index] = entitySlot{generation: id.Generation(), alive: true}
+	}
+
+	// 倒序放入，优先复用较小的索引
+	a.freeList = a.freeList[:0]
+	for index := len(a.slots) - 1; index > 0; index-- {
+		if !a.slots[index].alive {
+			a.freeList = append(a.freeList, uint32(index))
+		}
+	}
+}
+
+func (a *entityAllocator) isAlive(id EntityId) bool {
+	index := id.Index()
+	return index > 0 && int(index) < len(a.slots) &&
+		a.slots[index].alive && a.slots[index].generation == id.Generation()
+}
+
+func (a *entityAllocator) bind(entity IEntity) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := EntityId(entity.ID())
+	if a.isAlive(id) {
+		a.slots[id.Index()].entity = entity
+	}
+}
+
+// aliveIndices 按槽位顺序返回所有存活实体的索引
+func (a *entityAllocator) aliveIndices() []uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	indices := make([]uint64, 0, len(a.slots))
+	for index := 1; index < len(a.slots); index++ {
+		if a.slots[index].alive && a.slots[index].entity != nil {
+			indices = append(indices, uint64(index))
+		}
+	}
+	return indices
+}
+
+func (a *entityAllocator) get(index uint32) (IEntity, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if int(index) >= len(a.slots) || !a.slots[index].alive || a.slots[index].entity == nil {
+		return nil, false
+	}
+	return a.slots[index].entity, true
+}
+
+// AllocEntId 分配一个实体ID，优先复用已销毁实体的索引
+func (w *World) AllocEntId() uint64 {
+	return uint64(w.allocator.alloc())
+}
+
+// IncrEntId 分配一个实体ID
+//
+// Deprecated: 实体ID不再自增，请使用 AllocEntId
+func (w *World) IncrEntId() uint64 {
+	return w.AllocEntId()
+}
+
+// IsAlive 判断实体句柄是否仍然有效，索引被复用后旧的句柄会被拒绝
+func (w *World) IsAlive(entity IEntity) bool {
+	if entity == nil {
+		return false
+	}
+	return w.isAliveId(EntityId(entity.ID()))
+}
+
+func (w *World) isAliveId(entityId EntityId) bool {
+	w.allocator.mu.RLock()
+	defer w.allocator.mu.RUnlock()
+	return w.allocator.isAlive(entityId)
+}
+
+// entityAt 根据槽位索引获取当前存活的实体
+func (w *World) entityAt(index uint32) (IEntity, bool) {
+	return w.allocator.get(index)
+}
@@ -0,0 +1,152 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEntity_GenerationalIds(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			a := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			w.GetCommands().DestroyEntity(a).Execute()
+			if w.IsAlive(a) {
+				t.Fatalf("destroyed entity should not be alive")
+			}
+
+			b := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			oldId, newId := EntityId(a.ID()), EntityId(b.ID())
+			if newId.Index() != oldId.Index() {
+				t.Fatalf("expected index %d to be recycled, got %d", oldId.Index(), newId.Index())
+			}
+			if newId.Generation() != oldId.Generation()+1 {
+				t.Fatalf("expected generation %d, got %d", oldId.Generation()+1, newId.Generation())
+			}
+			if !w.IsAlive(b) {
+				t.Fatalf("new entity should be alive")
+			}
+
+			// 失效的句柄不能再修改实体
+			a.AddComponents(SpawnComponent[*testVelocity](w))
+			if n := len(w.GetQuery().Query(&testVelocity{})); n != 0 {
+				t.Fatalf("stale handle should not add components, got %d", n)
+			}
+
+			// 重复销毁失效的句柄不会影响复用了索引的新实体
+			w.GetCommands().DestroyEntity(a).Execute()
+			if got := w.GetQuery().Query(&testPosition{}); len(got) != 1 || got[0].ID() != b.ID() {
+				t.Fatalf("expected only the new entity, got %v", got)
+			}
+		})
+	}
+}
+
+func TestEntity_StaleHandleReads(t *testing.T) {
+	w := NewWorld(WithStorageMode(StorageSparseSet))
+	a := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	w.GetCommands().DestroyEntity(a).Execute()
+
+	b := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	if EntityId(a.ID()).Index() != EntityId(b.ID()).Index() {
+		t.Fatalf("expected the slot to be reused")
+	}
+
+	// 失效的句柄不能读到复用了槽位的新实体的组件
+	if GetComponent[*testPosition](a) != nil {
+		t.Fatalf("stale handle should not resolve components")
+	}
+	if w.GetQuery().Has(a, &testPosition{}) {
+		t.Fatalf("stale handle should not match Query.Has")
+	}
+	q := NewQuery1[*testPosition](w)
+	if _, ok := q.Get(a); ok {
+		t.Fatalf("stale handle should not match typed queries")
+	}
+	if q.Matches(a) {
+		t.Fatalf("stale handle should not match typed queries")
+	}
+	if len(a.GetComponentContainer()) != 0 {
+		t.Fatalf("component container should be cleared on destroy")
+	}
+	if GetComponent[*testPosition](b) == nil {
+		t.Fatalf("new entity should keep its component")
+	}
+}
+
+type testParallelSpawnSystem struct {
+	System
+	arrive *sync.WaitGroup
+	spawn  bool
+	seen   int
+}
+
+func (s *testParallelSpawnSystem) Update() {
+	s.arrive.Done()
+	s.arrive.Wait()
+	for i := 0; i < 200; i++ {
+		if s.spawn {
+			s.Commands.Spawn(&testVelocity{})
+			continue
+		}
+		s.seen = NewQuery1[*testPosition](s).Count()
+	}
+}
+
+// 一个系统通过 Commands.Spawn 分配实体ID的同时另一个系统查询实体，需要配合 -race 运行
+func TestEntity_SpawnWhileQueryingInParallel(t *testing.T) {
+	w := NewWorld(WithParallelism(2), WithStorageMode(StorageSparseSet))
+	for i := 0; i < 100; i++ {
+		SpawnEmptyEntity(w, &testPosition{})
+	}
+
+	arrive := &sync.WaitGroup{}
+	spawner := &testParallelSpawnSystem{System: *NewSystem(w), arrive: arrive, spawn: true}
+	spawner.Writes(&testVelocity{})
+	reader := &testParallelSpawnSystem{System: *NewSystem(w), arrive: arrive}
+	reader.Reads(&testPosition{})
+	w.AddUpdateSystem(spawner)
+	w.AddUpdateSystem(reader)
+
+	arrive.Add(2)
+	w.Update()
+	if reader.seen != 100 {
+		t.Fatalf("expected 100 entities, got %d", reader.seen)
+	}
+	if n := NewQuery1[*testVelocity](w).Count(); n != 200 {
+		t.Fatalf("expected 200 spawned entities, got %d", n)
+	}
+}
+
+// testMockWorld 外部包只实现导出的方法也能满足 IWorld
+type testMockWorld struct {
+	nextId uint64
+}
+
+func (m *testMockWorld) IncrEntId() uint64                               { return m.AllocEntId() }
+func (m *testMockWorld) AllocEntId() uint64                              { m.nextId++; return m.nextId }
+func (m *testMockWorld) IsAlive(entity IEntity) bool                     { return entity != nil }
+func (m *testMockWorld) GetResId(t reflect.Type) uint64                  { return 1 }
+func (m *testMockWorld) GetCompId(t reflect.Type) uint64                 { return 1 }
+func (m *testMockWorld) GetRelationId(t reflect.Type) uint64             { return 1 }
+func (m *testMockWorld) GetCommands() *Commands                          { return nil }
+func (m *testMockWorld) GetQuery() *Query                                { return nil }
+func (m *testMockWorld) GetComponentMap() map[ComponentId]IComponentInfo { return nil }
+func (m *testMockWorld) GetEntities() map[EntityId]IEntity               { return nil }
+func (m *testMockWorld) GetStorageMode() StorageMode                     { return StorageSparseSet }
+
+func TestEntity_MockWorld(t *testing.T) {
+	var w IWorld = &testMockWorld{}
+	if NewEntity(w).ID() != 1 || w.IncrEntId() != 2 {
+		t.Fatalf("mock world should allocate ids")
+	}
+	if c := NewPool[*testPosition](w).Create(); c == nil || c.ID() != 1 {
+		t.Fatalf("pool should work with a mock world")
+	}
+
+	// 已废弃的 IncrEntId 与 AllocEntId 相同
+	real := NewWorld()
+	if id := EntityId(real.IncrEntId()); !real.isAliveId(id) {
+		t.Fatalf("IncrEntId should allocate a live entity id")
+	}
+}
@@ -1,24 +1,107 @@
 package ecs
 
-type IEventData[T any] interface {
-	Has() bool
-	Get() T
-	Set(T)
-	Clear()
-}
+import "sync"
 
+// EventData 双缓冲的事件存储：current 保存本帧发送的事件，previous 保存上一帧的事件，
+// 每次 Update 丢弃 previous 并交换缓冲，因此事件可以存活两帧
 type EventData[T any] struct {
-	IEventData[T]
-	data T
+	mu            sync.RWMutex
+	previous      []T
+	current       []T
+	previousStart uint64 // previous 中第一个事件的序号
+	currentStart  uint64 // current 中第一个事件的序号
+	count         uint64 // 已发送的事件总数
 }
 
 func NewEventData[T any]() *EventData[T] {
-	return &EventData[T]{}
+	return &EventData[T]{
+		previous: make([]T, 0),
+		current:  make([]T, 0),
+	}
+}
+
+func (d *EventData[T]) send(data T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current = append(d.current, data)
+	d.count++
+}
+
+// Update 交换缓冲，上一帧的事件被丢弃
+func (d *EventData[T]) Update() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero T
+	for i := range d.previous {
+		d.previous[i] = zero
+	}
+	d.previous, d.current = d.current, d.previous[:0]
+	d.previousStart = d.currentStart
+	d.currentStart = d.count
+}
+
+// Clear 丢弃所有事件
+func (d *EventData[T]) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.previous = d.previous[:0]
+	d.current = d.current[:0]
+	d.previousStart = d.count
+	d.currentStart = d.count
+}
+
+// Len 仍然存活的事件数量
+func (d *EventData[T]) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.previous) + len(d.current)
+}
+
+// read 读取序号不小于 cursor 的事件，最多 limit 个（limit < 0 表示全部），返回新的 cursor
+func (d *EventData[T]) read(cursor uint64, limit int) ([]T, uint64) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// 已经被丢弃的事件直接跳过
+	if cursor < d.previousStart {
+		cursor = d.previousStart
+	}
+
+	events := make([]T, 0)
+	for _, buffer := range []struct {
+		start  uint64
+		events []T
+	}{{d.previousStart, d.previous}, {d.currentStart, d.current}} {
+		for i := range buffer.events {
+			if limit >= 0 && len(events) >= limit {
+				return events, cursor
+			}
+			seq := buffer.start + uint64(i)
+			if seq < cursor {
+				continue
+			}
+			events = append(events, buffer.events[i])
+			cursor = seq + 1
+		}
+	}
+	return events, cursor
+}
+
+func (d *EventData[T]) unread(cursor uint64) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if cursor < d.previousStart {
+		cursor = d.previousStart
+	}
+	if cursor >= d.count {
+		return 0
+	}
+	return int(d.count - cursor)
 }
 
 type Events[T any] struct {
 	data   *EventData[T]
-	reader *EventReader[T]
 	writer *EventWriter[T]
 }
 
@@ -26,39 +109,152 @@ func NewEvents[T any]() *Events[T] {
 	data := NewEventData[T]()
 	return &Events[T]{
 		data:   data,
-		reader: NewEventReader[T](data),
 		writer: NewEventWriter[T](data),
 	}
 }
 
+// Reader 创建一个新的读取器，每个读取器拥有独立的读取位置，可以读到当前仍然存活的所有事件
+func (e *Events[T]) Reader() *EventReader[T] {
+	return NewEventReader[T](e.data)
+}
+
+func (e *Events[T]) Writer() *EventWriter[T] {
+	return e.writer
+}
+
+// Update 每帧调用一次，交换事件缓冲
+func (e *Events[T]) Update() {
+	e.data.Update()
+}
+
+func (e *Events[T]) Clear() {
+	e.data.Clear()
+}
+
+func (e *Events[T]) Len() int {
+	return e.data.Len()
+}
+
 type EventReader[T any] struct {
-	data IEventData[T]
+	data   *EventData[T]
+	cursor uint64
 }
 
-func NewEventReader[T any](data IEventData[T]) *EventReader[T] {
+func NewEventReader[T any](data *EventData[T]) *EventReader[T] {
 	return &EventReader[T]{
 		data: data,
 	}
 }
 
+// Has 是否还有未读的事件
 func (e *EventReader[T]) Has() bool {
-	return e.data.Has()
+	return e.data.unread(e.cursor) > 0
+}
+
+// Len 未读事件的数量
+func (e *EventReader[T]) Len() int {
+	return e.data.unread(e.cursor)
+}
+
+// Get 读取下一个未读事件，没有时返回零值
+func (e *EventReader[T]) Get() T {
+	events, cursor := e.data.read(e.cursor, 1)
+	e.cursor = cursor
+	if len(events) == 0 {
+		var zero T
+		return zero
+	}
+	return events[0]
+}
+
+// Read 读取所有未读事件
+func (e *EventReader[T]) Read() []T {
+	events, cursor := e.data.read(e.cursor, -1)
+	e.cursor = cursor
+	return events
 }
 
-func (e *EventReader[T]) Get() interface{} {
-	return e.data.Get()
+// Clear 将所有未读事件标记为已读
+func (e *EventReader[T]) Clear() {
+	_, e.cursor = e.data.read(e.cursor, -1)
 }
 
 type EventWriter[T any] struct {
-	data IEventData[T]
+	data *EventData[T]
 }
 
-func NewEventWriter[T any](data IEventData[T]) *EventWriter[T] {
+func NewEventWriter[T any](data *EventData[T]) *EventWriter[T] {
 	return &EventWriter[T]{
 		data: data,
 	}
 }
 
 func (e *EventWriter[T]) Send(data T) {
-	e.data.Set(data)
+	e.data.send(data)
+}
+
+// SendBatch 按顺序发送多个事件
+func (e *EventWriter[T]) SendBatch(data ...T) {
+	for _, d := range data {
+		e.data.send(d)
+	}
+}
+
+// IEvents 注册到 World 的事件通道，World.Update 结束时统一交换缓冲
+type IEvents interface {
+	Update()
+	Clear()
+	Len() int
+}
+
+// AddEvent 在 World 中注册事件类型，重复注册返回已有的事件通道
+func AddEvent[T any](w *World) *Events[T] {
+	eventId := w.eventIdGetter.GetID(typeOf[T]())
+
+	w.eventMu.RLock()
+	events, ok := w.eventMap[eventId]
+	w.eventMu.RUnlock()
+	if ok {
+		return events.(*Events[T])
+	}
+
+	w.eventMu.Lock()
+	defer w.eventMu.Unlock()
+	if events, ok = w.eventMap[eventId]; ok {
+		return events.(*Events[T])
+	}
+	created := NewEvents[T]()
+	w.eventMap[eventId] = created
+	return created
+}
+
+// GetEvents 获取已注册的事件通道
+func GetEvents[T any](w *World) (*Events[T], bool) {
+	eventId := w.eventIdGetter.GetID(typeOf[T]())
+
+	w.eventMu.RLock()
+	defer w.eventMu.RUnlock()
+	events, ok := w.eventMap[eventId]
+	if !ok {
+		return nil, false
+	}
+	return events.(*Events[T]), true
+}
+
+// GetEventWriter 获取事件写入器，事件类型未注册时自动注册
+func GetEventWriter[T any](w *World) *EventWriter[T] {
+	return AddEvent[T](w).Writer()
+}
+
+// GetEventReader 创建事件读取器，事件类型未注册时自动注册；读取器应在系统创建时获取并保存
+func GetEventReader[T any](w *World) *EventReader[T] {
+	return AddEvent[T](w).Reader()
+}
+
+func (w *World) updateEvents() {
+	w.eventMu.RLock()
+	defer w.eventMu.RUnlock()
+	for _, events := range w.eventMap {
+		events.Update()
+	}
 }
@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"testing"
+)
+
+func TestEvents_IndependentReaders(t *testing.T) {
+	events := NewEvents[int]()
+	a := events.Reader()
+	b := events.Reader()
+
+	events.Writer().Send(1)
+	events.Writer().Send(2)
+
+	if !a.Has() || a.Get() != 1 || a.Get() != 2 || a.Has() {
+		t.Fatalf("reader a should read 1, 2 in order")
+	}
+	if got := b.Read(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("reader b should read both events independently, got %v", got)
+	}
+
+	events.Writer().Send(3)
+	if got := a.Read(); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("reader a should only read the new event, got %v", got)
+	}
+}
+
+func TestEvents_DoubleBuffer(t *testing.T) {
+	events := NewEvents[string]()
+	reader := events.Reader()
+
+	events.Writer().Send("a")
+	events.Update()
+	// 事件在第一次 Update 之后仍然可读
+	if reader.Len() != 1 {
+		t.Fatalf("event should survive one update, got %d", reader.Len())
+	}
+
+	events.Writer().Send("b")
+	events.Update()
+	// 第二次 Update 之后 "a" 被丢弃，"b" 仍然存活
+	if got := reader.Read(); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("expected only b after two updates, got %v", got)
+	}
+
+	events.Update()
+	if events.Len() != 0 {
+		t.Fatalf("all events should be dropped, got %d", events.Len())
+	}
+	if reader.Has() {
+		t.Fatalf("reader should have nothing to read")
+	}
+}
+
+type testDamageEvent struct {
+	Amount int
+}
+
+type testEventSenderSystem struct {
+	System
+	writer *EventWriter[testDamageEvent]
+}
+
+func (s *testEventSenderSystem) Update() {
+	s.writer.Send(testDamageEvent{Amount: 10})
+}
+
+type testEventReceiverSystem struct {
+	System
+	reader   *EventReader[testDamageEvent]
+	received []int
+}
+
+func (s *testEventReceiverSystem) Update() {
+	for _, e := range s.reader.Read() {
+		s.received = append(s.received, e.Amount)
+	}
+}
+
+func TestWorld_Events(t *testing.T) {
+	w := NewWorld()
+	AddEvent[testDamageEvent](w)
+
+	// 接收系统在发送系统之前运行，本帧的事件在下一帧才会被读到
+	receiver := &testEventReceiverSystem{System: *NewSystem(w), reader: GetEventReader[testDamageEvent](w)}
+	sender := &testEventSenderSystem{System: *NewSystem(w), writer: GetEventWriter[testDamageEvent](w)}
+	w.AddUpdateSystem(receiver)
+	w.AddUpdateSystem(sender)
+
+	w.Update()
+	if len(receiver.received) != 0 {
+		t.Fatalf("no events should be read in the first frame, got %v", receiver.received)
+	}
+	w.Update()
+	if len(receiver.received) != 1 || receiver.received[0] != 10 {
+		t.Fatalf("event sent in the previous frame should be read once, got %v", receiver.received)
+	}
+
+	events, ok := GetEvents[testDamageEvent](w)
+	if !ok || events != AddEvent[testDamageEvent](w) {
+		t.Fatalf("AddEvent should return the registered events")
+	}
+	// 两帧前发送的事件已经被丢弃，只剩上一帧的事件
+	if events.Len() != 1 {
+		t.Fatalf("expected 1 live event after update, got %d", events.Len())
+	}
+}
@@ -0,0 +1,169 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Access 系统声明的组件和资源访问权限，用于判断两个系统能否并行运行
+type Access struct {
+	declared       bool
+	reads          map[ComponentId]struct{}
+	writes         map[ComponentId]struct{}
+	resourceReads  map[ComponentId]struct{}
+	resourceWrites map[ComponentId]struct{}
+}
+
+func NewAccess() *Access {
+	return &Access{
+		reads:          make(map[ComponentId]struct{}),
+		writes:         make(map[ComponentId]struct{}),
+		resourceReads:  make(map[ComponentId]struct{}),
+		resourceWrites: make(map[ComponentId]struct{}),
+	}
+}
+
+// Declared 是否声明过访问权限，未声明的系统会独占运行
+func (a *Access) Declared() bool {
+	return a.declared
+}
+
+// ConflictsWith 一方写入的组件或资源被另一方读写时冲突，未声明访问权限的系统与所有系统冲突
+func (a *Access) ConflictsWith(other *Access) bool {
+	if a == nil || other == nil || !a.declared || !other.declared {
+		return true
+	}
+	return writeConflicts(a.writes, other.reads, other.writes) ||
+		writeConflicts(other.writes, a.reads, a.writes) ||
+		writeConflicts(a.resourceWrites, other.resourceReads, other.resourceWrites) ||
+		writeConflicts(other.resourceWrites, a.resourceReads, a.resourceWrites)
+}
+
+func writeConflicts(writes map[ComponentId]struct{}, reads map[ComponentId]struct{}, otherWrites map[ComponentId]struct{}) bool {
+	for componentId := range writes {
+		if _, ok := reads[componentId]; ok {
+			return true
+		}
+		if _, ok := otherWrites[componentId]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *System) GetAccess() *Access {
+	return s.access
+}
+
+// Reads 声明系统只读访问的组件
+func (s *System) Reads(components ...IComponent) *System {
+	s.access.declared = true
+	for _, component := range components {
+		s.access.reads[ComponentId(s.World.GetCompId(reflect.TypeOf(component)))] = struct{}{}
+	}
+	return s
+}
+
+// Writes 声明系统会修改的组件
+func (s *System) Writes(components ...IComponent) *System {
+	s.access.declared = true
+	for _, component := range components {
+		s.access.writes[ComponentId(s.World.GetCompId(reflect.TypeOf(component)))] = struct{}{}
+	}
+	return s
+}
+
+// ReadsResource 声明系统只读访问的资源
+func (s *System) ReadsResource(resources ...IComponent) *System {
+	s.access.declared = true
+	for _, resource := range resources {
+		s.access.resourceReads[ComponentId(s.World.GetResId(reflect.TypeOf(resource)))] = struct{}{}
+	}
+	return s
+}
+
+// WritesResource 声明系统会修改的资源
+func (s *System) WritesResource(resources ...IComponent) *System {
+	s.access.declared = true
+	for _, resource := range resources {
+		s.access.resourceWrites[ComponentId(s.World.GetResId(reflect.TypeOf(resource)))] = struct{}{}
+	}
+	return s
+}
+
+type IAccessSystem interface {
+	GetAccess() *Access
+}
+
+func systemAccess(system ISystem) *Access {
+	if accessSystem, ok := system.(IAccessSystem); ok {
+		return accessSystem.GetAccess()
+	}
+	return nil
+}
+
+// WithParallelism 设置并行执行系统的工作协程数，小于等于 1 时顺序执行
+func WithParallelism(workers int) WorldOption {
+	return func(w *World) {
+		w.workers = workers
+	}
+}
+
+// runStage 执行一个阶段内的所有系统
+func (w *World) runStage(stage Stage) {
+	systems := w.schedule.Systems(stage)
+	if w.workers <= 1 || len(systems) <= 1 {
+		for _, system := range systems {
+			w.runSystem(system)
+		}
+		return
+	}
+	w.runStageParallel(systems, w.schedule.deps[stage])
+}
+
+func (w *World) runSystem(system ISystem) {
+	w.runWithTicks(systemTicks(system), func() {
+		runUpdate(system)
+	})
+}
+
+// runStageParallel 系统需要等待所有依赖的系统，以及排在它前面且访问冲突的系统运行结束，
+// 因此结果与顺序执行一致，其余系统在工作协程上并行运行
+func (w *World) runStageParallel(systems []ISystem, deps [][]int) {
+	accesses := make([]*Access, len(systems))
+	for i, system := range systems {
+		accesses[i] = systemAccess(system)
+	}
+
+	done := make([]chan struct{}, len(systems))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	workers := make(chan struct{}, w.workers)
+	var wg sync.WaitGroup
+	for i, system := range systems {
+		waitFor := make([]int, 0, len(deps[i]))
+		waitFor = append(waitFor, deps[i]...)
+		for j := 0; j < i; j++ {
+			if accesses[i].ConflictsWith(accesses[j]) {
+				waitFor = append(waitFor, j)
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, system ISystem, waitFor []int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			for _, j := range waitFor {
+				<-done[j]
+			}
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			w.runSystem(system)
+		}(i, system, waitFor)
+	}
+	wg.Wait()
+}
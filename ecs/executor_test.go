@@ -0,0 +1,86 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testRendezvousSystem struct {
+	System
+	arrive *sync.WaitGroup
+	met    *bool
+}
+
+func (s *testRendezvousSystem) Update() {
+	s.arrive.Done()
+	done := make(chan struct{})
+	go func() {
+		s.arrive.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		*s.met = true
+	case <-time.After(time.Second):
+	}
+}
+
+func TestExecutor_RunsDisjointSystemsInParallel(t *testing.T) {
+	w := NewWorld(WithParallelism(4))
+
+	var arrive sync.WaitGroup
+	arrive.Add(2)
+	metA, metB := false, false
+
+	a := &testRendezvousSystem{System: *NewSystem(w), arrive: &arrive, met: &metA}
+	a.Writes(&testPosition{})
+	b := &testRendezvousSystem{System: *NewSystem(w), arrive: &arrive, met: &metB}
+	b.Writes(&testVelocity{})
+
+	w.AddUpdateSystem(a)
+	w.AddUpdateSystem(b)
+	w.Update()
+
+	if !metA || !metB {
+		t.Fatalf("systems with disjoint access should run concurrently")
+	}
+}
+
+func TestExecutor_KeepsOrderForConflicts(t *testing.T) {
+	w := NewWorld(WithParallelism(4))
+	log := make([]string, 0)
+
+	for _, name := range []string{"a", "b", "c"} {
+		s := newTestOrderSystem(w, name, &log)
+		s.Writes(&testPosition{})
+		w.AddUpdateSystem(s)
+	}
+	// 未声明访问权限的系统独占运行
+	w.AddUpdateSystem(newTestOrderSystem(w, "d", &log))
+
+	for i := 0; i < 10; i++ {
+		log = log[:0]
+		w.Update()
+		if len(log) != 4 || log[0] != "a" || log[1] != "b" || log[2] != "c" || log[3] != "d" {
+			t.Fatalf("conflicting systems should keep their order, got %v", log)
+		}
+	}
+}
+
+func TestAccess_ConflictsWith(t *testing.T) {
+	w := NewWorld()
+	reader := NewSystem(w).Reads(&testPosition{})
+	otherReader := NewSystem(w).Reads(&testPosition{})
+	writer := NewSystem(w).Writes(&testPosition{})
+
+	if reader.GetAccess().ConflictsWith(otherReader.GetAccess()) {
+		t.Fatalf("two readers should not conflict")
+	}
+	if !reader.GetAccess().ConflictsWith(writer.GetAccess()) {
+		t.Fatalf("reader and writer should conflict")
+	}
+	if !NewSystem(w).GetAccess().ConflictsWith(reader.GetAccess()) {
+		t.Fatalf("undeclared access should conflict with everything")
+	}
+}
@@ -0,0 +1,302 @@
+package ecs
+
+import (
+	"reflect"
+	"strings"
+)
+
+type termKind int
+
+const (
+	termWith termKind = iota
+	termWithout
+	termOptional
+	termOr
+	termAdded
+	termChanged
+	termPair
+	termTag
+	termNotTag
+	termAddedValue
+	termChangedValue
+)
+
+// Term 查询过滤条件
+type Term struct {
+	kind   termKind
+	types  []reflect.Type
+	target EntityId // 关系条件的目标
+}
+
+func newTerm(kind termKind, components ...IComponent) Term {
+	types := make([]reflect.Type, 0, len(components))
+	for _, component := range components {
+		types = append(types, reflect.TypeOf(component))
+	}
+	return Term{kind: kind, types: types}
+}
+
+// With 实体必须包含所有指定组件
+func With(components ...IComponent) Term {
+	return newTerm(termWith, components...)
+}
+
+// Without 实体不能包含任何指定组件
+func Without(components ...IComponent) Term {
+	return newTerm(termWithout, components...)
+}
+
+// Optional 组件可有可无，不影响匹配结果；泛型查询中对应的组件不存在时为零值
+func Optional(components ...IComponent) Term {
+	return newTerm(termOptional, components...)
+}
+
+// Or 实体至少包含指定组件中的一个
+func Or(components ...IComponent) Term {
+	return newTerm(termOr, components...)
+}
+
+// Filter 编译后的过滤条件，组件类型已经转换为组件ID
+type Filter struct {
+	with     []ComponentId
+	without  []ComponentId
+	optional []ComponentId
+	or       [][]ComponentId
+	added    []ComponentId
+	changed  []ComponentId
+	pairs    []pairKey
+	tags     []ComponentId
+	notTags  []ComponentId
+	key      string
+}
+
+func NewFilter(w IWorld, terms ...Term) *Filter {
+	f := &Filter{
+		with:     make([]ComponentId, 0),
+		without:  make([]ComponentId, 0),
+		optional: make([]ComponentId, 0),
+		or:       make([][]ComponentId, 0),
+		added:    make([]ComponentId, 0),
+		changed:  make([]ComponentId, 0),
+		pairs:    make([]pairKey, 0),
+		tags:     make([]ComponentId, 0),
+		notTags:  make([]ComponentId, 0),
+	}
+	for _, term := range terms {
+		f.addTerm(w, term)
+	}
+	f.buildKey()
+	return f
+}
+
+func (f *Filter) addTerm(w IWorld, term Term) {
+	if term.kind == termPair {
+		for _, t := range term.types {
+			f.pairs = append(f.pairs, pairKey{w.GetRelationId(t), term.target})
+		}
+		return
+	}
+
+	componentIds := make([]ComponentId, 0, len(term.types))
+	for _, t := range term.types {
+		componentIds = append(componentIds, ComponentId(w.GetCompId(t)))
+	}
+
+	switch term.kind {
+	case termWith:
+		f.with = append(f.with, componentIds...)
+	case termWithout:
+		f.without = append(f.without, componentIds...)
+	case termOptional:
+		f.optional = append(f.optional, componentIds...)
+	case termOr:
+		if len(componentIds) > 0 {
+			f.or = append(f.or, componentIds)
+		}
+	case termAdded:
+		f.with = append(f.with, componentIds...)
+		f.added = append(f.added, componentIds...)
+	case termChanged:
+		f.with = append(f.with, componentIds...)
+		f.changed = append(f.changed, componentIds...)
+	case termTag:
+		f.tags = append(f.tags, componentIds...)
+	case termNotTag:
+		f.notTags = append(f.notTags, componentIds...)
+	case termAddedValue:
+		f.tags = append(f.tags, componentIds...)
+		f.added = append(f.added, componentIds...)
+	case termChangedValue:
+		f.tags = append(f.tags, componentIds...)
+		f.changed = append(f.changed, componentIds...)
+	}
+}
+
+func (f *Filter) buildKey() {
+	var sb strings.Builder
+	sb.WriteString("w:")
+	sb.WriteString(signatureKey(f.with))
+	sb.WriteString("|n:")
+	sb.WriteString(signatureKey(f.without))
+	for _, group := range f.or {
+		sb.WriteString("|o:")
+		sb.WriteString(signatureKey(group))
+	}
+	f.key = sb.String()
+}
+
+func (f *Filter) isOptional(componentId ComponentId) bool {
+	for _, id := range f.optional {
+		if id == componentId {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchArchetype(a *Archetype) bool {
+	for _, componentId := range f.with {
+		if !a.Has(componentId) {
+			return false
+		}
+	}
+	for _, componentId := range f.without {
+		if a.Has(componentId) {
+			return false
+		}
+	}
+	for _, group := range f.or {
+		matched := false
+		for _, componentId := range group {
+			if a.Has(componentId) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchEntity 通过组件的稀疏集判断实体是否满足条件，不需要访问实体的组件容器；失效的实体ID不匹配任何条件
+func (f *Filter) matchEntity(w *World, entityId EntityId) bool {
+	if !w.isAliveId(entityId) {
+		return false
+	}
+	for _, componentId := range f.with {
+		componentInfo, ok := w.componentMap[componentId]
+		if !ok || !componentInfo.Contains(entityId) {
+			return false
+		}
+	}
+	for _, componentId := range f.without {
+		if componentInfo, ok := w.componentMap[componentId]; ok && componentInfo.Contains(entityId) {
+			return false
+		}
+	}
+	for _, group := range f.or {
+		matched := false
+		for _, componentId := range group {
+			if componentInfo, ok := w.componentMap[componentId]; ok && componentInfo.Contains(entityId) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// hasDriver 是否有可以驱动遍历的必需组件或关系，没有时只能遍历所有实体
+func (f *Filter) hasDriver() bool {
+	return len(f.with) > 0 || len(f.pairs) > 0 || len(f.tags) > 0
+}
+
+// driver 选出实体数最少的必需组件或关系用来驱动遍历，ok 为 false 表示不可能有匹配结果
+func (f *Filter) driver(w *World) (density []uint64, ok bool) {
+	for _, componentId := range f.with {
+		componentInfo, exists := w.componentMap[componentId]
+		if !exists {
+			return nil, false
+		}
+		if density == nil || len(componentInfo.Density()) < len(density) {
+			density = componentInfo.Density()
+		}
+	}
+	for _, componentId := range f.tags {
+		componentInfo, exists := w.componentMap[componentId]
+		if !exists {
+			return nil, false
+		}
+		if density == nil || len(componentInfo.Density()) < len(density) {
+			density = componentInfo.Density()
+		}
+	}
+	for _, pair := range f.pairs {
+		if pairDensity := w.relations.density(pair); density == nil || len(pairDensity) < len(density) {
+			density = pairDensity
+		}
+	}
+	return density, true
+}
+
+func (f *Filter) hasTicks() bool {
+	return len(f.added) > 0 || len(f.changed) > 0
+}
+
+// hasRowTerms 是否有原型无法判断、需要逐个实体检查的条件
+func (f *Filter) hasRowTerms() bool {
+	return f.hasTicks() || len(f.pairs) > 0 || len(f.tags) > 0 || len(f.notTags) > 0
+}
+
+// matchRow 检查标签、关系和 Added / Changed 条件
+func (f *Filter) matchRow(w *World, entityId EntityId, ticks *runTicks) bool {
+	return f.matchTags(w, entityId) && f.matchPairs(w, entityId) && f.matchTicks(w, entityId, ticks)
+}
+
+// rangeFilter 遍历满足过滤条件的实体；原型存储模式下同时给出实体所在的原型和行号
+func (w *World) rangeFilter(f *Filter, ticks *runTicks, fn func(entity IEntity, archetype *Archetype, row int) bool) {
+	if w.storageMode == StorageArchetype {
+		for _, archetype := range w.archetypes.MatchFilter(f) {
+			entities := archetype.Entities()
+			for row := 0; row < len(entities); row++ {
+				if f.hasRowTerms() && !f.matchRow(w, EntityId(entities[row].ID()), ticks) {
+					continue
+				}
+				if !fn(entities[row], archetype, row) {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	// 没有必需组件时只能按槽位顺序遍历所有实体
+	var density []uint64
+	if !f.hasDriver() {
+		density = w.allocator.aliveIndices()
+	} else {
+		var ok bool
+		if density, ok = f.driver(w); !ok {
+			return
+		}
+	}
+
+	for _, index := range density {
+		entity, exists := w.entityAt(uint32(index))
+		if !exists {
+			continue
+		}
+		entityId := EntityId(entity.ID())
+		if !f.matchEntity(w, entityId) || !f.matchRow(w, entityId, ticks) {
+			continue
+		}
+		if !fn(entity, nil, 0) {
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package ecs
+
+// Parent 实体的父节点，保存的是实体ID，父节点被销毁后不会留下悬空的引用
+// 通过 Commands.SetParent / AddChild 设置，也可以直接添加，World 会同步维护父节点的 Children
+type Parent struct {
+	Component
+	Entity EntityId
+}
+
+func (p *Parent) Init() {
+	p.Entity = 0
+}
+
+// OnInsert 将实体登记到父节点的 Children 中
+func (p *Parent) OnInsert(w *World, entity IEntity) {
+	parent, ok := w.GetEntity(p.Entity)
+	if !ok {
+		return
+	}
+
+	childrenId := ComponentId(w.GetCompId(typeOf[*Children]()))
+	component, ok := w.getComponent(parent, childrenId)
+	if !ok {
+		// 与 AddComponents 相同，补充 Children 声明的依赖组件
+		component = SpawnComponent[*Children](w)
+		parent.AddComponents(component)
+	}
+	children := component.(*Children)
+	for _, child := range children.Entities {
+		if child == EntityId(entity.ID()) {
+			return
+		}
+	}
+	children.Entities = append(children.Entities, EntityId(entity.ID()))
+	w.markChanged(parent, childrenId)
+}
+
+// OnReplace 从原来的父节点的 Children 中移除，没有子节点时移除 Children
+func (p *Parent) OnReplace(w *World, entity IEntity) {
+	parent, ok := w.GetEntity(p.Entity)
+	if !ok {
+		return
+	}
+
+	childrenId := ComponentId(w.GetCompId(typeOf[*Children]()))
+	component, ok := w.getComponent(parent, childrenId)
+	if !ok {
+		return
+	}
+	children := component.(*Children)
+	for i, child := range children.Entities {
+		if child != EntityId(entity.ID()) {
+			continue
+		}
+		children.Entities = append(children.Entities[:i], children.Entities[i+1:]...)
+		if len(children.Entities) == 0 {
+			w.removeComponent(parent, childrenId)
+		} else {
+			w.markChanged(parent, childrenId)
+		}
+		return
+	}
+}
+
+// Children 实体的子节点，由 World 根据子节点的 Parent 维护，不要直接修改
+type Children struct {
+	Component
+	Entities []EntityId
+}
+
+func (c *Children) Init() {
+	c.Entities = make([]EntityId, 0)
+}
+
+// OnRemove 父节点被销毁或移除 Children 时，子节点成为根节点
+func (c *Children) OnRemove(w *World, entity IEntity) {
+	children := c.Entities
+	c.Entities = make([]EntityId, 0)
+
+	parentId := ComponentId(w.GetCompId(typeOf[*Parent]()))
+	for _, childId := range children {
+		if child, ok := w.GetEntity(childId); ok {
+			w.removeComponent(child, parentId)
+		}
+	}
+}
+
+// GetEntity 根据实体ID获取存活的实体
+func (w *World) GetEntity(entityId EntityId) (IEntity, bool) {
+	entity, ok := w.entities[entityId]
+	return entity, ok
+}
+
+// GetParent 获取实体的父节点
+func GetParent(entity IEntity) (IEntity, bool) {
+	parent := GetComponent[*Parent](entity)
+	if parent == nil {
+		return nil, false
+	}
+	p, ok := entity.GetEcsWorld().GetEntities()[parent.Entity]
+	return p, ok
+}
+
+// GetChildren 获取实体的所有子节点，按添加的顺序排列
+func GetChildren(entity IEntity) []IEntity {
+	result := make([]IEntity, 0)
+	children := GetComponent[*Children](entity)
+	if children == nil {
+		return result
+	}
+	entities := entity.GetEcsWorld().GetEntities()
+	for _, childId := range children.Entities {
+		if child, ok := entities[childId]; ok {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// RangeAncestors 从父节点开始依次向上遍历，fn 返回 false 时停止
+func RangeAncestors(entity IEntity, fn func(ancestor IEntity) bool) {
+	for {
+		parent, ok := GetParent(entity)
+		if !ok || !fn(parent) {
+			return
+		}
+		entity = parent
+	}
+}
+
+// RangeDescendants 深度优先遍历所有子孙节点，fn 返回 false 时停止
+func RangeDescendants(entity IEntity, fn func(descendant IEntity) bool) {
+	rangeDescendants(entity, fn)
+}
+
+func rangeDescendants(entity IEntity, fn func(descendant IEntity) bool) bool {
+	for _, child := range GetChildren(entity) {
+		if !fn(child) || !rangeDescendants(child, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAncestor 判断 ancestor 是否是 entity 本身或者它的祖先
+func isAncestor(ancestor IEntity, entity IEntity) bool {
+	if ancestor.ID() == entity.ID() {
+		return true
+	}
+	found := false
+	RangeAncestors(entity, func(a IEntity) bool {
+		found = a.ID() == ancestor.ID()
+		return !found
+	})
+	return found
+}
+
+// setParent 设置父节点，会形成环的设置会被忽略
+func (w *World) setParent(child IEntity, parent IEntity) {
+	if !w.IsAlive(child) || !w.IsAlive(parent) || isAncestor(child, parent) {
+		return
+	}
+	if current := GetComponent[*Parent](child); current != nil && current.Entity == EntityId(parent.ID()) {
+		return
+	}
+
+	// 与 AddComponents 相同，补充 Parent 声明的依赖组件
+	component := SpawnComponent[*Parent](w)
+	component.Entity = EntityId(parent.ID())
+	child.AddComponents(component)
+}
+
+// destroyRecursive 先销毁子孙节点再销毁自身
+func (w *World) destroyRecursive(entity IEntity) {
+	if !w.IsAlive(entity) {
+		return
+	}
+	for _, child := range GetChildren(entity) {
+		w.destroyRecursive(child)
+	}
+	w.destroy(entity)
+}
+
+// AddChild 将 child 设置为 parent 的子节点
+func (c *Commands) AddChild(parent IEntity, child IEntity) *Commands {
+	return c.SetParent(child, parent)
+}
+
+// SetParent 设置实体的父节点，实体原来的父节点会被替换
+func (c *Commands) SetParent(child IEntity, parent IEntity) *Commands {
+	return c.Add(func(w *World) {
+		w.setParent(child, parent)
+	})
+}
+
+// RemoveParent 移除实体的父节点，实体成为根节点
+func (c *Commands) RemoveParent(child IEntity) *Commands {
+	return c.Remove(child, &Parent{})
+}
+
+// DestroyRecursive 销毁实体及其所有子孙节点
+func (c *Commands) DestroyRecursive(entity IEntity) *Commands {
+	return c.Add(func(w *World) {
+		w.destroyRecursive(entity)
+	})
+}
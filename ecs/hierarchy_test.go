@@ -0,0 +1,125 @@
+package ecs
+
+import (
+	"testing"
+)
+
+func entityIds(entities []IEntity) []uint64 {
+	ids := make([]uint64, 0, len(entities))
+	for _, entity := range entities {
+		ids = append(ids, entity.ID())
+	}
+	return ids
+}
+
+func TestHierarchy_SetParent(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			root := SpawnEmptyEntity(w)
+			a := SpawnEmptyEntity(w)
+			b := SpawnEmptyEntity(w)
+			leaf := SpawnEmptyEntity(w)
+
+			w.GetCommands().AddChild(root, a).AddChild(root, b).AddChild(a, leaf)
+			// 会形成环的设置被忽略
+			w.GetCommands().SetParent(root, leaf)
+			w.GetCommands().Execute()
+
+			if got := entityIds(GetChildren(root)); len(got) != 2 || got[0] != a.ID() || got[1] != b.ID() {
+				t.Fatalf("root should have children a, b, got %v", got)
+			}
+			if _, ok := GetParent(root); ok {
+				t.Fatalf("root should not have a parent")
+			}
+
+			ancestors := make([]IEntity, 0)
+			RangeAncestors(leaf, func(ancestor IEntity) bool {
+				ancestors = append(ancestors, ancestor)
+				return true
+			})
+			if got := entityIds(ancestors); len(got) != 2 || got[0] != a.ID() || got[1] != root.ID() {
+				t.Fatalf("leaf ancestors should be a, root, got %v", got)
+			}
+
+			// 换到新的父节点后从原来的 Children 中移除，空的 Children 被移除
+			w.GetCommands().SetParent(leaf, b)
+			w.GetCommands().Execute()
+			if GetComponent[*Children](a) != nil {
+				t.Fatalf("a should have no children")
+			}
+			if parent, ok := GetParent(leaf); !ok || parent.ID() != b.ID() {
+				t.Fatalf("leaf should be a child of b")
+			}
+
+			descendants := make([]IEntity, 0)
+			RangeDescendants(root, func(descendant IEntity) bool {
+				descendants = append(descendants, descendant)
+				return true
+			})
+			if got := entityIds(descendants); len(got) != 3 || got[0] != a.ID() || got[1] != b.ID() || got[2] != leaf.ID() {
+				t.Fatalf("unexpected descendants %v", got)
+			}
+		})
+	}
+}
+
+func TestHierarchy_Destroy(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			root := SpawnEmptyEntity(w)
+			a := SpawnEmptyEntity(w)
+			b := SpawnEmptyEntity(w)
+			leaf := SpawnEmptyEntity(w)
+			w.GetCommands().AddChild(root, a).AddChild(root, b).AddChild(a, leaf)
+			w.GetCommands().Execute()
+
+			// 只销毁 b，root 的 Children 不会留下悬空的引用
+			w.GetCommands().DestroyEntity(b)
+			w.GetCommands().Execute()
+			if got := entityIds(GetChildren(root)); len(got) != 1 || got[0] != a.ID() {
+				t.Fatalf("root should only have a, got %v", got)
+			}
+
+			w.GetCommands().DestroyRecursive(a)
+			w.GetCommands().Execute()
+			if w.IsAlive(a) || w.IsAlive(leaf) {
+				t.Fatalf("a and its descendants should be destroyed")
+			}
+			if GetComponent[*Children](root) != nil {
+				t.Fatalf("root should have no children")
+			}
+
+			// 父节点被销毁后子节点成为根节点
+			child := SpawnEmptyEntity(w)
+			w.GetCommands().AddChild(root, child).DestroyEntity(root)
+			w.GetCommands().Execute()
+			if !w.IsAlive(child) || GetComponent[*Parent](child) != nil {
+				t.Fatalf("child should survive as a root")
+			}
+		})
+	}
+}
+
+func TestHierarchy_RequiredComponents(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			// 内置的层级组件也可以声明依赖，AddChild 时与 AddComponents 一样补充
+			RegisterComponent[*Parent](w, WithRequired(&testFrozen{}))
+			RegisterComponent[*Children](w, WithRequired(&testHealth{Value: 1}))
+
+			parent := SpawnEmptyEntity(w)
+			child := SpawnEmptyEntity(w)
+			w.GetCommands().AddChild(parent, child).Execute()
+
+			if GetComponent[*testFrozen](child) == nil {
+				t.Fatalf("requirements of Parent should be added to the child")
+			}
+			if h := GetComponent[*testHealth](parent); h == nil || h.Value != 1 {
+				t.Fatalf("requirements of Children should be added to the parent")
+			}
+			if p, ok := GetParent(child); !ok || p.ID() != parent.ID() {
+				t.Fatalf("hierarchy should still be set")
+			}
+		})
+	}
+}
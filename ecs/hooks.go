@@ -0,0 +1,106 @@
+package ecs
+
+// ComponentHook 组件生命周期回调，component 为触发回调的组件实例
+// 回调在结构性修改的过程中执行，需要修改实体或组件时应通过 Commands 延迟执行
+type ComponentHook func(w *World, entity IEntity, component IComponent)
+
+// LifecycleEvent 组件的生命周期事件，同时用于组件回调和观察者
+type LifecycleEvent int
+
+const (
+	OnAdd LifecycleEvent = iota
+	OnInsert
+	OnReplace
+	OnRemove
+)
+
+// ComponentHooks 某个组件类型的生命周期回调
+//   - OnAdd 实体第一次挂载该组件之后
+//   - OnInsert 组件被挂载之后，包括新增和替换
+//   - OnReplace 旧组件被替换或移除之前
+//   - OnRemove 组件被移除或实体被销毁之前
+type ComponentHooks struct {
+	hooks [4][]ComponentHook
+}
+
+func NewComponentHooks() *ComponentHooks {
+	return &ComponentHooks{}
+}
+
+func (h *ComponentHooks) OnAdd(hook ComponentHook) *ComponentHooks {
+	h.hooks[OnAdd] = append(h.hooks[OnAdd], hook)
+	return h
+}
+
+func (h *ComponentHooks) OnInsert(hook ComponentHook) *ComponentHooks {
+	h.hooks[OnInsert] = append(h.hooks[OnInsert], hook)
+	return h
+}
+
+func (h *ComponentHooks) OnReplace(hook ComponentHook) *ComponentHooks {
+	h.hooks[OnReplace] = append(h.hooks[OnReplace], hook)
+	return h
+}
+
+func (h *ComponentHooks) OnRemove(hook ComponentHook) *ComponentHooks {
+	h.hooks[OnRemove] = append(h.hooks[OnRemove], hook)
+	return h
+}
+
+// 组件也可以直接实现以下接口，组件自身的回调先于 World 中注册的回调执行
+
+type IOnAdd interface {
+	OnAdd(w *World, entity IEntity)
+}
+
+type IOnInsert interface {
+	OnInsert(w *World, entity IEntity)
+}
+
+type IOnReplace interface {
+	OnReplace(w *World, entity IEntity)
+}
+
+type IOnRemove interface {
+	OnRemove(w *World, entity IEntity)
+}
+
+// Hooks 获取组件类型的生命周期回调，用于注册回调
+func Hooks[T IComponent](w *World) *ComponentHooks {
+	componentId := ComponentId(w.GetCompId(typeOf[T]()))
+	hooks, ok := w.hooks[componentId]
+	if !ok {
+		hooks = NewComponentHooks()
+		w.hooks[componentId] = hooks
+	}
+	return hooks
+}
+
+func (w *World) runHooks(kind LifecycleEvent, entity IEntity, componentId ComponentId, component IComponent) {
+	switch kind {
+	case OnAdd:
+		if c, ok := component.(IOnAdd); ok {
+			c.OnAdd(w, entity)
+		}
+	case OnInsert:
+		if c, ok := component.(IOnInsert); ok {
+			c.OnInsert(w, entity)
+		}
+	case OnReplace:
+		if c, ok := component.(IOnReplace); ok {
+			c.OnReplace(w, entity)
+		}
+	case OnRemove:
+		if c, ok := component.(IOnRemove); ok {
+			c.OnRemove(w, entity)
+		}
+	}
+
+	if hooks, ok := w.hooks[componentId]; ok {
+		for _, hook := range hooks.hooks[kind] {
+			hook(w, entity, component)
+		}
+	}
+
+	w.observers.notify(w, kind, entity, componentId, component)
+}
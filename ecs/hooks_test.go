@@ -0,0 +1,78 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testBody struct {
+	Component
+	log *[]string
+}
+
+func (b *testBody) OnAdd(w *World, entity IEntity) {
+	*b.log = append(*b.log, "body add")
+}
+
+func (b *testBody) OnRemove(w *World, entity IEntity) {
+	*b.log = append(*b.log, "body remove")
+}
+
+func TestHooks_Lifecycle(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			log := make([]string, 0)
+			record := func(kind string) ComponentHook {
+				return func(w *World, entity IEntity, component IComponent) {
+					log = append(log, fmt.Sprintf("%s %v", kind, component.(*testPosition).X))
+				}
+			}
+			Hooks[*testPosition](w).
+				OnAdd(record("add")).
+				OnInsert(record("insert")).
+				OnReplace(record("replace")).
+				OnRemove(record("remove"))
+
+			pos := SpawnComponent[*testPosition](w)
+			pos.X = 1
+			entity := SpawnEmptyEntity(w, pos)
+
+			replaced := SpawnComponent[*testPosition](w)
+			replaced.X = 2
+			entity.AddComponents(replaced)
+
+			entity.RemoveComponents(&testPosition{})
+
+			expected := []string{"add 1", "insert 1", "replace 1", "insert 2", "replace 2", "remove 2"}
+			if !reflect.DeepEqual(log, expected) {
+				t.Fatalf("expected %v, got %v", expected, log)
+			}
+		})
+	}
+}
+
+func TestHooks_Destroy(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			log := make([]string, 0)
+			Hooks[*testBody](w).OnRemove(func(w *World, entity IEntity, component IComponent) {
+				// 实体销毁时回调中仍然可以访问其他组件
+				pos := GetComponent[*testPosition](entity)
+				log = append(log, fmt.Sprintf("world remove %v", pos != nil))
+			})
+
+			body := SpawnComponent[*testBody](w)
+			body.log = &log
+			entity := SpawnEmptyEntity(w, body, SpawnComponent[*testPosition](w))
+
+			w.GetCommands().DestroyEntity(entity)
+			w.GetCommands().Execute()
+
+			expected := []string{"body add", "body remove", "world remove true"}
+			if !reflect.DeepEqual(log, expected) {
+				t.Fatalf("expected %v, got %v", expected, log)
+			}
+		})
+	}
+}
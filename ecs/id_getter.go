@@ -1,26 +1,65 @@
 package ecs
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 )
 
 type IdentityGetter struct {
+	mu     sync.RWMutex
 	idIncr uint64
 	idMap  map[reflect.Type]uint64
+	used   map[uint64]reflect.Type
 }
 
 func NewIdentityGetter() *IdentityGetter {
 	return &IdentityGetter{
 		idMap: make(map[reflect.Type]uint64),
+		used:  make(map[uint64]reflect.Type),
 	}
 }
 
+// SetID 为类型指定固定的ID，必须在类型第一次获取ID之前调用；自动分配的ID会跳过已经指定的ID
+func (ig *IdentityGetter) SetID(t reflect.Type, id uint64) error {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+
+	if current, ok := ig.idMap[t]; ok {
+		if current == id {
+			return nil
+		}
+		return fmt.Errorf("ecs: type %s already has id %d", t, current)
+	}
+	if other, ok := ig.used[id]; ok {
+		return fmt.Errorf("ecs: id %d is already used by type %s", id, other)
+	}
+	ig.idMap[t] = id
+	ig.used[id] = t
+	return nil
+}
+
 func (ig *IdentityGetter) GetID(t reflect.Type) uint64 {
-	if id, ok := ig.idMap[t]; ok {
+	ig.mu.RLock()
+	id, ok := ig.idMap[t]
+	ig.mu.RUnlock()
+	if ok {
 		return id
 	}
 
-	ig.idIncr += 1
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+	if id, ok = ig.idMap[t]; ok {
+		return id
+	}
+
+	for {
+		ig.idIncr += 1
+		if _, ok = ig.used[ig.idIncr]; !ok {
+			break
+		}
+	}
 	ig.idMap[t] = ig.idIncr
+	ig.used[ig.idIncr] = t
 	return ig.idIncr
 }
@@ -0,0 +1,191 @@
+package ecs
+
+import "sync"
+
+// Trigger 观察者被触发时的上下文
+type Trigger struct {
+	World       *World
+	Entity      IEntity     // 目标实体，没有目标的自定义事件为 nil
+	ComponentId ComponentId // 触发生命周期事件的组件，自定义事件为 0
+	Component   IComponent  // 延迟执行时组件可能已经被回收，不应再访问
+	Event       any         // 自定义事件的数据，生命周期事件为触发的 LifecycleEvent
+}
+
+func (t *Trigger) Commands() *Commands {
+	return t.World.GetCommands()
+}
+
+func (t *Trigger) Query() *Query {
+	return t.World.GetQuery()
+}
+
+// Observer 监听组件生命周期事件或自定义事件的回调
+type Observer struct {
+	w          *World
+	components map[ComponentId]struct{}
+	filter     *Filter
+	deferred   bool
+	fn         func(trigger *Trigger)
+}
+
+type ObserverOption func(o *Observer)
+
+// ObserveComponents 只监听指定组件的生命周期事件，不设置时监听所有组件
+func ObserveComponents(components ...IComponent) ObserverOption {
+	return func(o *Observer) {
+		for _, componentId := range o.w.GetQuery().componentIds(components...) {
+			o.components[componentId] = struct{}{}
+		}
+	}
+}
+
+// ObserveFilter 目标实体需要满足过滤条件，Added / Changed 条件会被忽略
+func ObserveFilter(terms ...Term) ObserverOption {
+	return func(o *Observer) {
+		o.filter = NewFilter(o.w, terms...)
+	}
+}
+
+// Deferred 观察者在下一个命令同步点执行，而不是在事件发生时立即执行
+func Deferred() ObserverOption {
+	return func(o *Observer) {
+		o.deferred = true
+	}
+}
+
+func newObserver(w *World, fn func(trigger *Trigger), opts ...ObserverOption) *Observer {
+	o := &Observer{
+		w:          w,
+		components: make(map[ComponentId]struct{}),
+		fn:         fn,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *Observer) matchComponent(componentId ComponentId) bool {
+	if len(o.components) == 0 {
+		return true
+	}
+	_, ok := o.components[componentId]
+	return ok
+}
+
+func (o *Observer) matchEntity(w *World, entity IEntity) bool {
+	return o.filter == nil || (o.filter.matchEntity(w, EntityId(entity.ID())) && o.filter.matchTags(w, EntityId(entity.ID())) && o.filter.matchPairs(w, EntityId(entity.ID())))
+}
+
+func (o *Observer) run(w *World, trigger *Trigger) {
+	if o.deferred {
+		w.commands.Add(func(w *World) {
+			o.fn(trigger)
+		})
+		return
+	}
+	o.fn(trigger)
+}
+
+// Observers World 中注册的所有观察者
+type Observers struct {
+	mu        sync.RWMutex
+	lifecycle [4][]*Observer
+	custom    map[uint64][]*Observer
+}
+
+func NewObservers() *Observers {
+	return &Observers{
+		custom: make(map[uint64][]*Observer),
+	}
+}
+
+func (o *Observers) remove(observer *Observer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	removeFrom := func(list []*Observer) []*Observer {
+		kept := make([]*Observer, 0, len(list))
+		for _, other := range list {
+			if other != observer {
+				kept = append(kept, other)
+			}
+		}
+		return kept
+	}
+	for i := range o.lifecycle {
+		o.lifecycle[i] = removeFrom(o.lifecycle[i])
+	}
+	for eventId, list := range o.custom {
+		o.custom[eventId] = removeFrom(list)
+	}
+}
+
+// notify 组件生命周期事件发生时调用，在组件回调之后执行
+func (o *Observers) notify(w *World, kind LifecycleEvent, entity IEntity, componentId ComponentId, component IComponent) {
+	o.mu.RLock()
+	observers := o.lifecycle[kind]
+	o.mu.RUnlock()
+
+	for _, observer := range observers {
+		if !observer.matchComponent(componentId) || !observer.matchEntity(w, entity) {
+			continue
+		}
+		observer.run(w, &Trigger{
+			World:       w,
+			Entity:      entity,
+			ComponentId: componentId,
+			Component:   component,
+			Event:       kind,
+		})
+	}
+}
+
+// Observe 注册组件生命周期事件的观察者
+func (w *World) Observe(event LifecycleEvent, fn func(trigger *Trigger), opts ...ObserverOption) *Observer {
+	observer := newObserver(w, fn, opts...)
+	w.observers.mu.Lock()
+	w.observers.lifecycle[event] = append(w.observers.lifecycle[event], observer)
+	w.observers.mu.Unlock()
+	return observer
+}
+
+// RemoveObserver 移除观察者
+func (w *World) RemoveObserver(observer *Observer) {
+	w.observers.remove(observer)
+}
+
+// ObserveEvent 注册自定义事件的观察者，通过 TriggerEvent 触发
+func ObserveEvent[E any](w *World, fn func(trigger *Trigger, event E), opts ...ObserverOption) *Observer {
+	observer := newObserver(w, func(trigger *Trigger) {
+		event, _ := trigger.Event.(E)
+		fn(trigger, event)
+	}, opts...)
+
+	eventId := w.eventIdGetter.GetID(typeOf[E]())
+	w.observers.mu.Lock()
+	w.observers.custom[eventId] = append(w.observers.custom[eventId], observer)
+	w.observers.mu.Unlock()
+	return observer
+}
+
+// TriggerEvent 触发自定义事件，指定目标时对每个满足观察者过滤条件的实体分别触发一次
+func TriggerEvent[E any](w *World, event E, targets ...IEntity) {
+	eventId := w.eventIdGetter.GetID(typeOf[E]())
+	w.observers.mu.RLock()
+	observers := w.observers.custom[eventId]
+	w.observers.mu.RUnlock()
+
+	for _, observer := range observers {
+		if len(targets) == 0 {
+			observer.run(w, &Trigger{World: w, Event: event})
+			continue
+		}
+		for _, target := range targets {
+			if !w.IsAlive(target) || !observer.matchEntity(w, target) {
+				continue
+			}
+			observer.run(w, &Trigger{World: w, Entity: target, Event: event})
+		}
+	}
+}
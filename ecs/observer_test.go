@@ -0,0 +1,82 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testHealth struct {
+	Component
+	Value int
+}
+
+type testCorpse struct {
+	Component
+	X float64
+}
+
+func TestObserver_SpawnOnRemove(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			// 失去生命值的实体留下一具尸体，尸体在下一个同步点生成
+			w.Observe(OnRemove, func(trigger *Trigger) {
+				pos := GetComponent[*testPosition](trigger.Entity)
+				corpse := SpawnComponent[*testCorpse](trigger.World)
+				corpse.X = pos.X
+				trigger.Commands().Spawn(corpse)
+			}, ObserveComponents(&testHealth{}), ObserveFilter(With(&testPosition{})))
+
+			pos := SpawnComponent[*testPosition](w)
+			pos.X = 3
+			entity := SpawnEmptyEntity(w, pos, SpawnComponent[*testHealth](w))
+			// 没有位置的实体不满足过滤条件
+			other := SpawnEmptyEntity(w, SpawnComponent[*testHealth](w))
+
+			entity.RemoveComponents(&testHealth{})
+			w.GetCommands().DestroyEntity(other)
+			w.GetCommands().Execute()
+
+			corpses := w.GetQuery().Query(&testCorpse{})
+			if len(corpses) != 1 || GetComponent[*testCorpse](corpses[0]).X != 3 {
+				t.Fatalf("expected one corpse at x=3, got %d", len(corpses))
+			}
+		})
+	}
+}
+
+type testExplode struct {
+	Radius int
+}
+
+func TestObserver_CustomEvent(t *testing.T) {
+	w := NewWorld()
+	log := make([]string, 0)
+
+	ObserveEvent[testExplode](w, func(trigger *Trigger, event testExplode) {
+		log = append(log, "immediate")
+	})
+	observer := ObserveEvent[testExplode](w, func(trigger *Trigger, event testExplode) {
+		if trigger.Entity == nil || event.Radius != 2 {
+			t.Fatalf("unexpected trigger %v %v", trigger.Entity, event)
+		}
+		log = append(log, "deferred")
+	}, ObserveFilter(With(&testPosition{})), Deferred())
+
+	a := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	b := SpawnEmptyEntity(w)
+	TriggerEvent(w, testExplode{Radius: 2}, a, b)
+	log = append(log, "flush")
+	w.GetCommands().Execute()
+
+	expected := []string{"immediate", "immediate", "flush", "deferred"}
+	if !reflect.DeepEqual(log, expected) {
+		t.Fatalf("expected %v, got %v", expected, log)
+	}
+
+	w.RemoveObserver(observer)
+	TriggerEvent(w, testExplode{Radius: 2}, a)
+	w.GetCommands().Execute()
+	if len(log) != 5 {
+		t.Fatalf("removed observer should not run, got %v", log)
+	}
+}
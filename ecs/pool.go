@@ -2,59 +2,187 @@ package ecs
 
 import (
 	"reflect"
+	"sync"
 
 	"github.com/INT-Game/go-ecs/array"
 )
 
+// PoolStats 对象池的统计信息
+type PoolStats struct {
+	Live      int // 正在使用的实例数
+	Cached    int // 缓存等待复用的实例数
+	Allocated int // 累计新分配的实例数
+}
+
+type PoolOption func(p *poolConfig)
+
+type poolConfig struct {
+	maxCached     int
+	zeroOnRecycle bool
+}
+
+// WithMaxCached 最多缓存的实例数，超出时销毁的实例直接丢弃；小于等于 0 时不限制
+func WithMaxCached(n int) PoolOption {
+	return func(p *poolConfig) {
+		p.maxCached = n
+	}
+}
+
+// WithZeroOnRecycle 复用缓存的实例时先清零，再调用 Init
+func WithZeroOnRecycle(enabled bool) PoolOption {
+	return func(p *poolConfig) {
+		p.zeroOnRecycle = enabled
+	}
+}
+
+// WithPoolDefaults 设置所有组件对象池的默认配置，单个组件可以通过 GetPool 再次配置
+func WithPoolDefaults(opts ...PoolOption) WorldOption {
+	return func(w *World) {
+		w.poolDefaults = append(w.poolDefaults, opts...)
+	}
+}
+
+type IPool interface {
+	Configure(opts ...PoolOption)
+	Trim(n int)
+	Stats() PoolStats
+}
+
 type Pool[T IComponent] struct {
+	IPool
+	mu        sync.Mutex
 	w         IWorld
+	t         reflect.Type
+	config    poolConfig
 	instances array.Array[IComponent]
+	slots     map[IComponent]int // 实例在 instances 中的位置，销毁时不需要线性查找
 	caches    array.Array[IComponent]
+	allocated int
 }
 
 func NewPool[T IComponent](w IWorld) *Pool[T] {
-	return &Pool[T]{
+	return newPoolOf[T](w, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// newPoolOf 按运行时的类型创建对象，T 为 IComponent 时用于事先未知类型的组件
+func newPoolOf[T IComponent](w IWorld, t reflect.Type) *Pool[T] {
+	p := &Pool[T]{
 		w:         w,
+		t:         t,
 		instances: array.New[IComponent](),
+		slots:     make(map[IComponent]int),
 		caches:    array.New[IComponent](),
 	}
+	if internals, ok := w.(worldInternals); ok {
+		for _, opt := range internals.getPoolDefaults() {
+			opt(&p.config)
+		}
+	}
+	return p
 }
 
 func (p *Pool[T]) Create() T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	componentId := p.w.GetCompId(p.t)
+	var component IComponent
 	if !p.caches.Empty() {
-		component := p.caches.Back()
+		component = p.caches.Back()
 		p.caches.PopBack()
-		component.Init()
-		p.instances.PushBack(component)
+		if p.config.zeroOnRecycle {
+			v := reflect.ValueOf(component).Elem()
+			v.Set(reflect.Zero(v.Type()))
+			component.SetID(componentId)
+		}
 	} else {
-		componentId := p.w.GetCompId(reflect.TypeOf((*T)(nil)).Elem())
-		component := p.doCreate()
+		component = p.doCreate()
 		component.SetID(componentId)
-		component.Init()
-		p.instances.PushBack(component)
+		p.allocated++
 	}
+	component.Init()
 
-	return p.instances.Back().(T)
+	p.slots[component] = p.instances.Len()
+	p.instances.PushBack(component)
+	return component.(T)
 }
 
 func (p *Pool[T]) doCreate() T {
-	t := reflect.TypeOf((*T)(nil)).Elem()
-	if t.Kind() == reflect.Ptr {
-		v := reflect.New(t.Elem())
+	if p.t.Kind() == reflect.Ptr {
+		v := reflect.New(p.t.Elem())
 		return v.Interface().(T)
 	}
-	return reflect.New(t).Interface().(T)
+	return reflect.New(p.t).Interface().(T)
 }
 
 func (p *Pool[T]) Destroy(elem IComponent) {
-	if i, ok := p.instances.Contain(elem); ok {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i, ok := p.slots[elem]
+	if !ok {
+		return
+	}
+
+	// 与最后一个实例交换后删除，被交换的实例更新位置
+	last := p.instances.Len() - 1
+	p.instances.Swap(i, last)
+	p.slots[p.instances[i]] = i
+	p.instances[last] = nil
+	p.instances.PopBack()
+	delete(p.slots, elem)
+
+	if p.config.maxCached <= 0 || p.caches.Len() < p.config.maxCached {
 		p.caches.PushBack(elem)
-		p.instances.Swap(i, p.instances.Len()-1)
-		p.instances.PopBack()
+	}
 
-		// 调用销毁函数进行资源清理
-		if elem.Destroy != nil {
-			elem.Destroy()
-		}
+	// 调用销毁函数进行资源清理
+	elem.Destroy()
+}
+
+// Configure 修改对象池的配置，缓存超出新的上限时立即裁剪
+func (p *Pool[T]) Configure(opts ...PoolOption) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+	if p.config.maxCached > 0 {
+		p.trim(p.config.maxCached)
+	}
+}
+
+// Trim 丢弃多余的缓存实例，最多保留 n 个
+func (p *Pool[T]) Trim(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.trim(n)
+}
+
+func (p *Pool[T]) trim(n int) {
+	if n < 0 {
+		n = 0
+	}
+	for p.caches.Len() > n {
+		p.caches[p.caches.Len()-1] = nil
+		p.caches.PopBack()
 	}
 }
+
+func (p *Pool[T]) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		Live:      p.instances.Len(),
+		Cached:    p.caches.Len(),
+		Allocated: p.allocated,
+	}
+}
+
+// GetPool 组件类型的对象池，可以用来修改配置和查看统计信息
+//
+//	ecs.GetPool[*Bullet](w).Configure(ecs.WithMaxCached(256))
+func GetPool[T IComponent](w *World) IPool {
+	return w.componentMap[w.ensureComponent(typeOf[T]())].getPool()
+}
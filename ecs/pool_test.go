@@ -0,0 +1,81 @@
+package ecs
+
+import "testing"
+
+func TestPool_DestroyAndStats(t *testing.T) {
+	w := NewWorld()
+	pool := NewPool[*testPosition](w)
+
+	components := make([]*testPosition, 0)
+	for i := 0; i < 5; i++ {
+		components = append(components, pool.Create())
+	}
+	pool.Destroy(components[1])
+	pool.Destroy(components[4])
+	pool.Destroy(components[1]) // 重复销毁被忽略
+
+	if s := pool.Stats(); s != (PoolStats{Live: 3, Cached: 2, Allocated: 5}) {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	// 交换后其余实例仍然可以被正确销毁
+	pool.Destroy(components[0])
+	pool.Destroy(components[2])
+	pool.Destroy(components[3])
+	if s := pool.Stats(); s.Live != 0 || s.Cached != 5 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	pool.Create()
+	if s := pool.Stats(); s.Allocated != 5 || s.Cached != 4 {
+		t.Fatalf("cached instance should be reused, got %+v", s)
+	}
+}
+
+func TestPool_MaxCached(t *testing.T) {
+	w := NewWorld()
+	pool := NewPool[*testPosition](w)
+
+	components := make([]*testPosition, 0)
+	for i := 0; i < 4; i++ {
+		components = append(components, pool.Create())
+	}
+	for _, component := range components {
+		pool.Destroy(component)
+	}
+
+	pool.Configure(WithMaxCached(2))
+	if s := pool.Stats(); s.Cached != 2 {
+		t.Fatalf("cache should be trimmed to 2, got %+v", s)
+	}
+	a, b, c := pool.Create(), pool.Create(), pool.Create()
+	pool.Destroy(a)
+	pool.Destroy(b)
+	pool.Destroy(c)
+	if s := pool.Stats(); s.Cached != 2 || s.Allocated != 5 {
+		t.Fatalf("cache should not exceed 2, got %+v", s)
+	}
+
+	pool.Trim(0)
+	if s := pool.Stats(); s.Cached != 0 {
+		t.Fatalf("cache should be empty, got %+v", s)
+	}
+}
+
+func TestPool_ZeroOnRecycle(t *testing.T) {
+	w := NewWorld(WithPoolDefaults(WithZeroOnRecycle(true)))
+	entity := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	GetComponent[*testPosition](entity).X = 5
+	entity.RemoveComponents(&testPosition{})
+
+	component := SpawnComponent[*testPosition](w)
+	if component.X != 0 {
+		t.Fatalf("recycled component should be zeroed, got %v", component.X)
+	}
+	if component.ID() != w.GetCompId(typeOf[*testPosition]()) {
+		t.Fatalf("recycled component should keep its component id")
+	}
+	if s := GetPool[*testPosition](w).Stats(); s.Allocated != 1 || s.Live != 1 {
+		t.Fatalf("component should be reused, got %+v", s)
+	}
+}
@@ -0,0 +1,198 @@
+package ecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Prefab 一组组件模板，实例化时每个组件都会被深拷贝，子预制体通过父子层级挂在实例下
+type Prefab struct {
+	Name       string
+	Components []IComponent
+	Children   []*Prefab
+}
+
+func NewPrefab(name string, components ...IComponent) *Prefab {
+	return &Prefab{
+		Name:       name,
+		Components: components,
+		Children:   make([]*Prefab, 0),
+	}
+}
+
+// AddChild 添加子预制体，实例化时作为子节点一起创建
+func (p *Prefab) AddChild(children ...*Prefab) *Prefab {
+	p.Children = append(p.Children, children...)
+	return p
+}
+
+// deepCopy 复制值，切片、映射和指针指向的数据也会被复制，接口和未导出字段只做浅拷贝
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type().Elem())
+		n.Elem().Set(deepCopy(v.Elem()))
+		return n
+	case reflect.Struct:
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < n.NumField(); i++ {
+			if n.Field(i).CanSet() {
+				n.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return n
+	case reflect.Array:
+		n := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// cloneComponent 从组件池中创建实例并复制模板的数据
+func (w *World) cloneComponent(template IComponent) IComponent {
+	componentId := w.ensureComponent(reflect.TypeOf(template))
+	component := w.componentMap[componentId].CreateComponent()
+	reflect.ValueOf(component).Elem().Set(deepCopy(reflect.ValueOf(template).Elem()))
+	component.SetID(uint64(componentId))
+	return component
+}
+
+// instantiate 为已经分配好ID的实体添加预制体的组件，overrides 中的组件代替同类型的模板
+func (w *World) instantiate(entity IEntity, prefab *Prefab, overrides ...IComponent) {
+	overrides = expandBundles(w, overrides)
+	overridden := make(map[reflect.Type]bool, len(overrides))
+	for _, component := range overrides {
+		overridden[reflect.TypeOf(component)] = true
+	}
+
+	components := make([]IComponent, 0, len(prefab.Components)+len(overrides))
+	for _, template := range prefab.Components {
+		bundle, ok := template.(IBundle)
+		if !ok {
+			if !overridden[reflect.TypeOf(template)] {
+				components = append(components, w.cloneComponent(template))
+			}
+			continue
+		}
+		// 组合中设置了的字段作为模板复制，未设置的字段创建默认组件
+		rangeBundle(bundle, func(t reflect.Type, template IComponent) {
+			switch {
+			case overridden[t]:
+			case template == nil:
+				components = append(components, w.componentMap[w.ensureComponent(t)].CreateComponent())
+			default:
+				components = append(components, w.cloneComponent(template))
+			}
+		})
+	}
+	components = append(components, overrides...)
+	w.commands.doSpawn(entity, components...)
+
+	for _, childPrefab := range prefab.Children {
+		child := NewEntity(w)
+		w.instantiate(child, childPrefab)
+		w.setParent(child, entity)
+	}
+}
+
+// Instantiate 立即根据预制体创建实体，overrides 中的组件直接挂载到实体上，代替预制体中同类型的组件
+func (w *World) Instantiate(prefab *Prefab, overrides ...IComponent) IEntity {
+	entity := NewEntity(w)
+	w.instantiate(entity, prefab, overrides...)
+	return entity
+}
+
+// Instantiate 立即分配实体ID并返回句柄，预制体的组件和子节点在命令执行时才会创建
+func (c *Commands) Instantiate(prefab *Prefab, overrides ...IComponent) IEntity {
+	entity := NewEntity(c.w)
+	c.Add(func(w *World) {
+		w.instantiate(entity, prefab, overrides...)
+	})
+	return entity
+}
+
+// prefabData 预制体的文件格式，components 以注册表中的类型名称为键
+type prefabData struct {
+	Name       string                     `json:"name"`
+	Components map[string]json.RawMessage `json:"components"`
+	Children   []prefabData               `json:"children"`
+}
+
+func (w *World) buildPrefab(data prefabData) (*Prefab, error) {
+	prefab := NewPrefab(data.Name)
+
+	// 按名称排序，保证组件的添加顺序稳定
+	names := make([]string, 0, len(data.Components))
+	for name := range data.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		componentType, ok := w.registry.Component(name)
+		if !ok {
+			return nil, fmt.Errorf("ecs: prefab %s: component type %s is not registered", data.Name, name)
+		}
+		t := componentType.Type
+		if t.Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("ecs: prefab %s: component type %s must be a pointer", data.Name, name)
+		}
+		template := reflect.New(t.Elem()).Interface().(IComponent)
+		template.Init()
+		if err := json.Unmarshal(data.Components[name], template); err != nil {
+			return nil, fmt.Errorf("ecs: prefab %s: decode component %s: %w", data.Name, name, err)
+		}
+		prefab.Components = append(prefab.Components, template)
+	}
+
+	for _, childData := range data.Children {
+		child, err := w.buildPrefab(childData)
+		if err != nil {
+			return nil, err
+		}
+		prefab.AddChild(child)
+	}
+	return prefab, nil
+}
+
+// LoadPrefab 从 JSON 读取预制体，组件类型需要事先通过 RegisterComponent 注册
+// 只支持 JSON，YAML 等其他格式需要调用方先转换为 JSON
+//
+//	{"name": "enemy", "components": {"game.Health": {"Value": 10}}, "children": [...]}
+func (w *World) LoadPrefab(reader io.Reader) (*Prefab, error) {
+	var data prefabData
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		return nil, fmt.Errorf("ecs: decode prefab: %w", err)
+	}
+	return w.buildPrefab(data)
+}
@@ -0,0 +1,85 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+)
+
+type testInventory struct {
+	Component
+	Items []string
+}
+
+func TestPrefab_Instantiate(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			weapon := NewPrefab("weapon", &testVelocity{X: 1})
+			enemy := NewPrefab("enemy", &testPosition{X: 1}, &testInventory{Items: []string{"potion"}}).AddChild(weapon)
+
+			a := w.Instantiate(enemy)
+			b := w.Instantiate(enemy, &testPosition{X: 5})
+
+			// 模板中的切片被深拷贝，实例之间互不影响
+			GetComponent[*testInventory](a).Items[0] = "sword"
+			if got := GetComponent[*testInventory](b).Items[0]; got != "potion" {
+				t.Fatalf("instances should not share slices, got %s", got)
+			}
+			if got := enemy.Components[1].(*testInventory).Items[0]; got != "potion" {
+				t.Fatalf("template should not be modified, got %s", got)
+			}
+
+			if GetComponent[*testPosition](a).X != 1 || GetComponent[*testPosition](b).X != 5 {
+				t.Fatalf("override should replace the template component")
+			}
+
+			children := GetChildren(a)
+			if len(children) != 1 || GetComponent[*testVelocity](children[0]).X != 1 {
+				t.Fatalf("nested prefab should be instantiated as a child")
+			}
+			if n := NewQuery1[*testVelocity](w).Count(); n != 2 {
+				t.Fatalf("expected 2 weapons, got %d", n)
+			}
+		})
+	}
+}
+
+func TestPrefab_Commands(t *testing.T) {
+	w := NewWorld()
+	prefab := NewPrefab("bullet", &testPosition{X: 3})
+
+	entity := w.GetCommands().Instantiate(prefab)
+	if GetComponent[*testPosition](entity) != nil {
+		t.Fatalf("components should be added when commands are executed")
+	}
+	w.GetCommands().Execute()
+	if p := GetComponent[*testPosition](entity); p == nil || p.X != 3 {
+		t.Fatalf("prefab should be instantiated")
+	}
+}
+
+func TestPrefab_Load(t *testing.T) {
+	w := NewWorld()
+	RegisterComponent[*testPosition](w, WithTypeName("test.Position"))
+	RegisterComponent[*testInventory](w, WithTypeName("test.Inventory"))
+
+	prefab, err := w.LoadPrefab(strings.NewReader(`{
+		"name": "chest",
+		"components": {"test.Position": {"X": 2}, "test.Inventory": {"Items": ["gold"]}},
+		"children": [{"name": "lid", "components": {"test.Position": {"Y": 1}}}]
+	}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	entity := w.Instantiate(prefab)
+	if GetComponent[*testPosition](entity).X != 2 || GetComponent[*testInventory](entity).Items[0] != "gold" {
+		t.Fatalf("components should be decoded")
+	}
+	if children := GetChildren(entity); len(children) != 1 || GetComponent[*testPosition](children[0]).Y != 1 {
+		t.Fatalf("child prefab should be decoded")
+	}
+
+	if _, err = w.LoadPrefab(strings.NewReader(`{"name": "bad", "components": {"test.Unknown": {}}}`)); err == nil {
+		t.Fatalf("unknown component type should fail")
+	}
+}
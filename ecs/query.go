@@ -3,12 +3,20 @@ package ecs
 import "reflect"
 
 type Query struct {
-	w *World
+	w        *World
+	ticks    *runTicks
+	commands *Commands
 }
 
 func NewQuery(w *World) *Query {
+	return newQueryWithTicks(w, w.ticks, w.commands)
+}
+
+func newQueryWithTicks(w *World, ticks *runTicks, commands *Commands) *Query {
 	return &Query{
-		w: w,
+		w:        w,
+		ticks:    ticks,
+		commands: commands,
 	}
 }
 
@@ -19,6 +27,10 @@ func (q *Query) Query(components ...IComponent) []IEntity {
 		return entities
 	}
 
+	if q.w.storageMode == StorageArchetype {
+		return q.queryArchetypes(components...)
+	}
+
 	component := components[0]
 	remains := components[1:]
 
@@ -27,8 +39,7 @@ func (q *Query) Query(components ...IComponent) []IEntity {
 		density := componentInfo.Density()
 		for i := 0; i < len(density); i++ {
 			var entity IEntity
-			entityId := EntityId(density[i])
-			if entity, ok = q.w.entities[entityId]; ok {
+			if entity, ok = q.w.entityAt(uint32(density[i])); ok {
 				if q.doQueryRemains(entity, remains...) {
 					entities = append(entities, entity)
 				}
@@ -39,6 +50,45 @@ func (q *Query) Query(components ...IComponent) []IEntity {
 	return entities
 }
 
+// Filter 查询满足所有过滤条件的实体，条件通过组件的稀疏集判断
+func (q *Query) Filter(terms ...Term) []IEntity {
+	entities := make([]IEntity, 0)
+	q.w.rangeFilter(NewFilter(q.w, terms...), q.ticks, func(entity IEntity, archetype *Archetype, row int) bool {
+		entities = append(entities, entity)
+		return true
+	})
+	return entities
+}
+
+// queryArchetypes 匹配包含所有组件的原型，并按行顺序收集实体
+func (q *Query) queryArchetypes(components ...IComponent) []IEntity {
+	entities := make([]IEntity, 0)
+	for _, archetype := range q.w.archetypes.Match(q.componentIds(components...)) {
+		entities = append(entities, archetype.Entities()...)
+	}
+	return entities
+}
+
+// RangeArchetypes 遍历包含所有指定组件的原型表，仅在原型存储模式下有效
+func (q *Query) RangeArchetypes(fn func(archetype *Archetype), components ...IComponent) {
+	if q.w.storageMode != StorageArchetype {
+		return
+	}
+	for _, archetype := range q.w.archetypes.Match(q.componentIds(components...)) {
+		if archetype.Len() > 0 {
+			fn(archetype)
+		}
+	}
+}
+
+func (q *Query) componentIds(components ...IComponent) []ComponentId {
+	componentIds := make([]ComponentId, 0, len(components))
+	for _, component := range components {
+		componentIds = append(componentIds, ComponentId(q.w.GetCompId(reflect.TypeOf(component))))
+	}
+	return componentIds
+}
+
 func (q *Query) doQueryRemains(e IEntity, components ...IComponent) bool {
 	if len(components) == 0 {
 		return true
@@ -48,7 +98,7 @@ func (q *Query) doQueryRemains(e IEntity, components ...IComponent) bool {
 	remains := components[1:]
 
 	componentId := q.w.GetCompId(reflect.TypeOf(component))
-	if _, ok := e.GetComponentContainer()[ComponentId(componentId)]; ok {
+	if _, ok := q.w.getComponent(e, ComponentId(componentId)); ok {
 		return q.doQueryRemains(e, remains...)
 	}
 
@@ -58,7 +108,7 @@ func (q *Query) doQueryRemains(e IEntity, components ...IComponent) bool {
 // Has 判断实体是否包含指定组件
 func (q *Query) Has(e IEntity, c IComponent) bool {
 	componentId := q.w.GetCompId(reflect.TypeOf(c))
-	if _, ok := e.GetComponentContainer()[ComponentId(componentId)]; ok {
+	if _, ok := q.w.getComponent(e, ComponentId(componentId)); ok {
 		return true
 	}
 
@@ -82,9 +132,18 @@ func (q *Query) Get(e IEntity, c IComponent) (IComponent, bool) {
 	}
 
 	componentId := q.w.GetCompId(reflect.TypeOf(c))
-	if component, ok := e.GetComponentContainer()[ComponentId(componentId)]; ok {
+	if component, ok := q.w.getComponent(e, ComponentId(componentId)); ok {
 		return component, true
 	}
 
 	return nil, false
 }
+
+// GetMut 以可变方式获取实体的指定组件，组件会被标记为已修改
+func (q *Query) GetMut(e IEntity, c IComponent) (IComponent, bool) {
+	component, ok := q.Get(e, c)
+	if ok {
+		q.w.markChangedIn(e, ComponentId(q.w.GetCompId(reflect.TypeOf(c))), q.ticks)
+	}
+	return component, ok
+}
@@ -0,0 +1,171 @@
+package ecs
+
+import (
+	"runtime"
+	"sync"
+)
+
+const minChunkSize = 64
+
+// queryChunk 并行遍历时的一段连续区间：原型存储模式下是原型表中的若干行，
+// 稀疏集模式下是驱动组件 density 中的一段实体索引
+type queryChunk struct {
+	archetype *Archetype
+	density   []uint64
+	start     int
+	end       int
+}
+
+// SetChunkSize 设置并行遍历时每段的实体数，小于等于 0 时根据匹配数量和工作协程数自动计算
+func (q *typedQuery) SetChunkSize(chunkSize int) {
+	q.chunkSize = chunkSize
+}
+
+func (q *typedQuery) parallelism() int {
+	if q.w.workers > 1 {
+		return q.w.workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func (q *typedQuery) chunkSizeFor(total int, workers int) int {
+	if q.chunkSize > 0 {
+		return q.chunkSize
+	}
+	size := total / (workers * 4)
+	if size < minChunkSize {
+		size = minChunkSize
+	}
+	return size
+}
+
+// chunks 将匹配的区间切分成若干段
+func (q *typedQuery) chunks(workers int) []queryChunk {
+	chunks := make([]queryChunk, 0)
+
+	if q.w.storageMode == StorageArchetype {
+		archetypes := q.w.archetypes.MatchFilter(q.filter)
+		total := 0
+		for _, archetype := range archetypes {
+			total += archetype.Len()
+		}
+		size := q.chunkSizeFor(total, workers)
+		for _, archetype := range archetypes {
+			for start := 0; start < archetype.Len(); start += size {
+				chunks = append(chunks, queryChunk{archetype: archetype, start: start, end: min(start+size, archetype.Len())})
+			}
+		}
+		return chunks
+	}
+
+	var density []uint64
+	if !q.filter.hasDriver() {
+		// 按槽位顺序切分，分段和合并顺序在每次运行之间保持一致
+		density = q.w.allocator.aliveIndices()
+	} else {
+		var ok bool
+		if density, ok = q.filter.driver(q.w); !ok {
+			return chunks
+		}
+	}
+
+	size := q.chunkSizeFor(len(density), workers)
+	for start := 0; start < len(density); start += size {
+		chunks = append(chunks, queryChunk{density: density, start: start, end: min(start+size, len(density))})
+	}
+	return chunks
+}
+
+// rangeChunk 遍历一段区间内满足条件的实体
+func (q *typedQuery) rangeChunk(chunk queryChunk, row []IComponent, fn func(entity IEntity, row []IComponent)) {
+	if chunk.archetype != nil {
+		columns := make([][]IComponent, len(q.ids))
+		for i, componentId := range q.ids {
+			columns[i], _ = chunk.archetype.Column(componentId)
+		}
+		entities := chunk.archetype.Entities()
+		for r := chunk.start; r < chunk.end; r++ {
+			if q.filter.hasRowTerms() && !q.filter.matchRow(q.w, EntityId(entities[r].ID()), q.ticks) {
+				continue
+			}
+			for i := range columns {
+				row[i] = nil
+				if columns[i] != nil {
+					row[i] = columns[i][r]
+				}
+			}
+			fn(entities[r], row)
+		}
+		return
+	}
+
+	for _, index := range chunk.density[chunk.start:chunk.end] {
+		entity, ok := q.w.entityAt(uint32(index))
+		if !ok {
+			continue
+		}
+		entityId := EntityId(entity.ID())
+		if !q.filter.matchEntity(q.w, entityId) || !q.filter.matchRow(q.w, entityId, q.ticks) {
+			continue
+		}
+		if !q.fetch(entity, row) {
+			continue
+		}
+		fn(entity, row)
+	}
+}
+
+// parRangeRows 将匹配的实体分段后交给有限个工作协程处理；
+// 每段拥有独立的命令缓冲，全部完成后按分段顺序合并到查询所属系统（或世界）的命令缓冲，结果与协程调度无关
+func (q *typedQuery) parRangeRows(fn func(cmds *Commands, entity IEntity, row []IComponent)) {
+	workers := q.parallelism()
+	chunks := q.chunks(workers)
+	if len(chunks) == 0 {
+		return
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	buffers := make([]*Commands, len(chunks))
+	for i := range buffers {
+		buffers[i] = NewCommands(q.w)
+	}
+
+	next := make(chan int, len(chunks))
+	for i := range chunks {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			row := make([]IComponent, len(q.ids))
+			for i := range next {
+				cmds := buffers[i]
+				q.rangeChunk(chunks[i], row, func(entity IEntity, row []IComponent) {
+					fn(cmds, entity, row)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	q.commands.Append(buffers...)
+}
+
+// ParForEach 并行遍历满足过滤条件的实体，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query) ParForEach(fn func(cmds *Commands, entity IEntity), terms ...Term) {
+	tq := typedQuery{
+		w:        q.w,
+		ticks:    q.ticks,
+		commands: q.commands,
+		filter:   NewFilter(q.w, terms...),
+	}
+	tq.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity)
+	})
+}
@@ -0,0 +1,199 @@
+package ecs
+
+import (
+	"testing"
+)
+
+func newTestWorlds() map[string]*World {
+	return map[string]*World{
+		"sparse_set": NewWorld(),
+		"archetype":  NewWorld(WithStorageMode(StorageArchetype)),
+	}
+}
+
+func TestQuery2_ForEach(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				pos := SpawnComponent[*testPosition](w)
+				vel := SpawnComponent[*testVelocity](w)
+				vel.X = 1
+				SpawnEmptyEntity(w, pos, vel)
+			}
+			SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+
+			q := NewQuery2[*testPosition, *testVelocity](w)
+			if n := q.Count(); n != 3 {
+				t.Fatalf("expected 3 matches, got %d", n)
+			}
+
+			q.ForEach(func(entity IEntity, pos *testPosition, vel *testVelocity) {
+				pos.X += vel.X
+			})
+
+			moved := 0
+			NewQuery1[*testPosition](w).ForEach(func(entity IEntity, pos *testPosition) {
+				if pos.X == 1 {
+					moved++
+				}
+			})
+			if moved != 3 {
+				t.Fatalf("expected 3 moved positions, got %d", moved)
+			}
+
+			for _, entity := range q.Entities() {
+				if _, _, ok := q.Get(entity); !ok {
+					t.Fatalf("Get failed for matched entity %d", entity.ID())
+				}
+			}
+		})
+	}
+}
+
+type testFrozen struct {
+	Component
+}
+
+func TestQuery_Filter(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			moving := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w), SpawnComponent[*testVelocity](w))
+			frozen := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w), SpawnComponent[*testVelocity](w), SpawnComponent[*testFrozen](w))
+			still := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			SpawnEmptyEntity(w, SpawnComponent[*testFrozen](w))
+
+			q := w.GetQuery()
+			if got := q.Filter(With(&testPosition{}, &testVelocity{}), Without(&testFrozen{})); len(got) != 1 || got[0].ID() != moving.ID() {
+				t.Fatalf("With/Without: unexpected result %v", got)
+			}
+			if got := q.Filter(With(&testPosition{}), Or(&testVelocity{}, &testFrozen{})); len(got) != 2 {
+				t.Fatalf("Or: expected 2 entities, got %d", len(got))
+			}
+			if got := q.Filter(Without(&testPosition{})); len(got) != 1 {
+				t.Fatalf("Without only: expected 1 entity, got %d", len(got))
+			}
+
+			withVel := 0
+			NewQuery2[*testPosition, *testVelocity](w, Optional(&testVelocity{})).ForEach(func(entity IEntity, pos *testPosition, vel *testVelocity) {
+				if vel != nil {
+					withVel++
+				}
+			})
+			if withVel != 2 {
+				t.Fatalf("Optional: expected 2 entities with velocity, got %d", withVel)
+			}
+
+			q2 := NewQuery1[*testPosition](w, Without(&testFrozen{}))
+			if _, ok := q2.Get(frozen); ok {
+				t.Fatalf("Get should reject entity excluded by Without")
+			}
+			if _, ok := q2.Get(still); !ok {
+				t.Fatalf("Get should accept matching entity")
+			}
+		})
+	}
+}
+
+func TestQuery2_ParForEach(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				pos := SpawnComponent[*testPosition](w)
+				pos.X = float64(i)
+				vel := SpawnComponent[*testVelocity](w)
+				vel.X = 1
+				SpawnEmptyEntity(w, pos, vel)
+			}
+
+			queued := make([]uint64, 0)
+			q := NewQuery2[*testPosition, *testVelocity](w)
+			q.SetChunkSize(100)
+			q.ParForEach(func(cmds *Commands, entity IEntity, pos *testPosition, vel *testVelocity) {
+				pos.X += vel.X
+				if int(pos.X)%2 == 0 {
+					id := entity.ID()
+					cmds.Add(func(w *World) {
+						queued = append(queued, id)
+					}).DestroyEntity(entity)
+				}
+			})
+
+			// 合并后的命令顺序与顺序遍历一致
+			expected := make([]uint64, 0)
+			q.ForEach(func(entity IEntity, pos *testPosition, vel *testVelocity) {
+				if int(pos.X)%2 == 0 {
+					expected = append(expected, entity.ID())
+				}
+			})
+			w.GetCommands().Execute()
+			if len(queued) != len(expected) {
+				t.Fatalf("expected %d queued destroys, got %d", len(expected), len(queued))
+			}
+			for i := range expected {
+				if queued[i] != expected[i] {
+					t.Fatalf("queued destroys are not in deterministic order at %d", i)
+				}
+			}
+
+			if n := q.Count(); n != 500 {
+				t.Fatalf("expected 500 entities left, got %d", n)
+			}
+		})
+	}
+}
+
+func TestQuery_ParForEachWithoutDriver(t *testing.T) {
+	w := NewWorld(WithStorageMode(StorageSparseSet))
+	expected := make([]uint64, 0)
+	for i := 0; i < 200; i++ {
+		entity := SpawnEmptyEntity(w)
+		if i%3 == 0 {
+			entity.AddComponents(&testFrozen{})
+			continue
+		}
+		expected = append(expected, entity.ID())
+	}
+
+	// 没有必需组件时按槽位顺序分段，合并后的命令顺序在每次运行之间保持一致
+	for run := 0; run < 5; run++ {
+		queued := make([]uint64, 0)
+		w.GetQuery().ParForEach(func(cmds *Commands, entity IEntity) {
+			id := entity.ID()
+			cmds.Add(func(w *World) {
+				queued = append(queued, id)
+			})
+		}, Without(&testFrozen{}))
+		w.GetCommands().Execute()
+
+		if len(queued) != len(expected) {
+			t.Fatalf("expected %d entities, got %d", len(expected), len(queued))
+		}
+		for i := range expected {
+			if queued[i] != expected[i] {
+				t.Fatalf("run %d: merge order differs at %d", run, i)
+			}
+		}
+	}
+}
+
+// 工作协程中通过 cmds.Spawn 分配实体ID的同时其他协程解析槽位，需要配合 -race 运行
+func TestQuery_ParForEachSpawn(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				SpawnEmptyEntity(w, &testPosition{X: float64(i)})
+			}
+
+			q := NewQuery1[*testPosition](w)
+			q.SetChunkSize(50)
+			q.ParForEach(func(cmds *Commands, entity IEntity, pos *testPosition) {
+				cmds.Spawn(&testVelocity{X: pos.X})
+			})
+			w.GetCommands().Execute()
+
+			if n := NewQuery1[*testVelocity](w).Count(); n != 1000 {
+				t.Fatalf("expected 1000 spawned entities, got %d", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package ecs
+
+import "reflect"
+
+// typedQuery 泛型查询的公共部分，组件ID在构造时根据类型一次性计算
+type typedQuery struct {
+	w         *World
+	ticks     *runTicks
+	commands  *Commands // 并行遍历时各段的命令合并到这里
+	ids       []ComponentId
+	optional  []bool
+	filter    *Filter
+	chunkSize int
+}
+
+func newTypedQuery(ctx QueryContext, types []reflect.Type, terms ...Term) typedQuery {
+	w := ctx.GetWorld()
+	filter := NewFilter(w, terms...)
+	ids := make([]ComponentId, 0, len(types))
+	optional := make([]bool, 0, len(types))
+	for _, t := range types {
+		componentId := ComponentId(w.GetCompId(t))
+		ids = append(ids, componentId)
+		optional = append(optional, filter.isOptional(componentId))
+		if !filter.isOptional(componentId) {
+			filter.with = append(filter.with, componentId)
+		}
+	}
+	filter.buildKey()
+
+	return typedQuery{
+		w:        w,
+		ticks:    ctx.getTicks(),
+		commands: ctx.getCommands(),
+		ids:      ids,
+		optional: optional,
+		filter:   filter,
+	}
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// cast 可选组件不存在时返回零值
+func cast[T IComponent](component IComponent) T {
+	if component == nil {
+		var zero T
+		return zero
+	}
+	return component.(T)
+}
+
+// rangeRows 遍历所有匹配的实体，row 中按构造顺序存放对应的组件，回调返回 false 时停止遍历
+func (q *typedQuery) rangeRows(fn func(entity IEntity, row []IComponent) bool) {
+	row := make([]IComponent, len(q.ids))
+	columns := make([][]IComponent, len(q.ids))
+	var current *Archetype
+
+	q.w.rangeFilter(q.filter, q.ticks, func(entity IEntity, archetype *Archetype, r int) bool {
+		if archetype == nil {
+			if !q.fetch(entity, row) {
+				return true
+			}
+			return fn(entity, row)
+		}
+
+		// 同一原型内的列只需要查找一次
+		if archetype != current {
+			current = archetype
+			for i, componentId := range q.ids {
+				columns[i], _ = archetype.Column(componentId)
+			}
+		}
+		for i := range columns {
+			row[i] = nil
+			if columns[i] != nil {
+				row[i] = columns[i][r]
+			}
+		}
+		return fn(entity, row)
+	})
+}
+
+func (q *typedQuery) fetch(entity IEntity, row []IComponent) bool {
+	for i, componentId := range q.ids {
+		component, ok := q.w.getComponent(entity, componentId)
+		if !ok && !q.optional[i] {
+			return false
+		}
+		row[i] = component
+	}
+	return true
+}
+
+// Count 匹配的实体数量
+func (q *typedQuery) Count() int {
+	count := 0
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// Entities 匹配的所有实体
+func (q *typedQuery) Entities() []IEntity {
+	entities := make([]IEntity, 0)
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		entities = append(entities, entity)
+		return true
+	})
+	return entities
+}
+
+// Matches 判断实体是否满足查询条件
+func (q *typedQuery) Matches(entity IEntity) bool {
+	if !q.w.IsAlive(entity) {
+		return false
+	}
+	entityId := EntityId(entity.ID())
+	if q.w.storageMode == StorageArchetype {
+		loc, ok := q.w.archetypes.locations[entityId]
+		if !ok || !q.filter.matchArchetype(loc.archetype) {
+			return false
+		}
+	} else if !q.filter.matchEntity(q.w, entityId) {
+		return false
+	}
+	return q.filter.matchRow(q.w, entityId, q.ticks)
+}
+
+// Query1 返回 1 个强类型组件的查询
+type Query1[A IComponent] struct {
+	typedQuery
+}
+
+func NewQuery1[A IComponent](ctx QueryContext, terms ...Term) *Query1[A] {
+	return &Query1[A]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query1[A]) ForEach(fn func(entity IEntity, a A)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query1[A]) ParForEach(fn func(cmds *Commands, entity IEntity, a A)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query1[A]) Get(entity IEntity) (A, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		return a, false
+	}
+	return cast[A](row[0]), true
+}
+
+// Query2 返回 2 个强类型组件的查询
+type Query2[A, B IComponent] struct {
+	typedQuery
+}
+
+func NewQuery2[A, B IComponent](ctx QueryContext, terms ...Term) *Query2[A, B] {
+	return &Query2[A, B]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query2[A, B]) ForEach(fn func(entity IEntity, a A, b B)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query2[A, B]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query2[A, B]) Get(entity IEntity) (A, B, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		return a, b, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), true
+}
+
+// Query3 返回 3 个强类型组件的查询
+type Query3[A, B, C IComponent] struct {
+	typedQuery
+}
+
+func NewQuery3[A, B, C IComponent](ctx QueryContext, terms ...Term) *Query3[A, B, C] {
+	return &Query3[A, B, C]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query3[A, B, C]) ForEach(fn func(entity IEntity, a A, b B, c C)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query3[A, B, C]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query3[A, B, C]) Get(entity IEntity) (A, B, C, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		return a, b, c, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), true
+}
+
+// Query4 返回 4 个强类型组件的查询
+type Query4[A, B, C, D IComponent] struct {
+	typedQuery
+}
+
+func NewQuery4[A, B, C, D IComponent](ctx QueryContext, terms ...Term) *Query4[A, B, C, D] {
+	return &Query4[A, B, C, D]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query4[A, B, C, D]) ForEach(fn func(entity IEntity, a A, b B, c C, d D)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query4[A, B, C, D]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C, d D)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query4[A, B, C, D]) Get(entity IEntity) (A, B, C, D, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		var d D
+		return a, b, c, d, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), true
+}
+
+// Query5 返回 5 个强类型组件的查询
+type Query5[A, B, C, D, E IComponent] struct {
+	typedQuery
+}
+
+func NewQuery5[A, B, C, D, E IComponent](ctx QueryContext, terms ...Term) *Query5[A, B, C, D, E] {
+	return &Query5[A, B, C, D, E]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query5[A, B, C, D, E]) ForEach(fn func(entity IEntity, a A, b B, c C, d D, e E)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query5[A, B, C, D, E]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C, d D, e E)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query5[A, B, C, D, E]) Get(entity IEntity) (A, B, C, D, E, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		var d D
+		var e E
+		return a, b, c, d, e, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), true
+}
+
+// Query6 返回 6 个强类型组件的查询
+type Query6[A, B, C, D, E, F IComponent] struct {
+	typedQuery
+}
+
+func NewQuery6[A, B, C, D, E, F IComponent](ctx QueryContext, terms ...Term) *Query6[A, B, C, D, E, F] {
+	return &Query6[A, B, C, D, E, F]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query6[A, B, C, D, E, F]) ForEach(fn func(entity IEntity, a A, b B, c C, d D, e E, f F)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query6[A, B, C, D, E, F]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C, d D, e E, f F)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query6[A, B, C, D, E, F]) Get(entity IEntity) (A, B, C, D, E, F, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		var d D
+		var e E
+		var f F
+		return a, b, c, d, e, f, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), true
+}
+
+// Query7 返回 7 个强类型组件的查询
+type Query7[A, B, C, D, E, F, G IComponent] struct {
+	typedQuery
+}
+
+func NewQuery7[A, B, C, D, E, F, G IComponent](ctx QueryContext, terms ...Term) *Query7[A, B, C, D, E, F, G] {
+	return &Query7[A, B, C, D, E, F, G]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F](), typeOf[G]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query7[A, B, C, D, E, F, G]) ForEach(fn func(entity IEntity, a A, b B, c C, d D, e E, f F, g G)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query7[A, B, C, D, E, F, G]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C, d D, e E, f F, g G)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query7[A, B, C, D, E, F, G]) Get(entity IEntity) (A, B, C, D, E, F, G, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		var d D
+		var e E
+		var f F
+		var g G
+		return a, b, c, d, e, f, g, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]), true
+}
+
+// Query8 返回 8 个强类型组件的查询
+type Query8[A, B, C, D, E, F, G, H IComponent] struct {
+	typedQuery
+}
+
+func NewQuery8[A, B, C, D, E, F, G, H IComponent](ctx QueryContext, terms ...Term) *Query8[A, B, C, D, E, F, G, H] {
+	return &Query8[A, B, C, D, E, F, G, H]{
+		typedQuery: newTypedQuery(ctx, []reflect.Type{typeOf[A](), typeOf[B](), typeOf[C](), typeOf[D](), typeOf[E](), typeOf[F](), typeOf[G](), typeOf[H]()}, terms...),
+	}
+}
+
+// ForEach 遍历所有匹配的实体及其组件
+func (q *Query8[A, B, C, D, E, F, G, H]) ForEach(fn func(entity IEntity, a A, b B, c C, d D, e E, f F, g G, h H)) {
+	q.rangeRows(func(entity IEntity, row []IComponent) bool {
+		fn(entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]), cast[H](row[7]))
+		return true
+	})
+}
+
+// ParForEach 分段并行遍历，回调中的结构性修改需要通过 cmds 延迟执行
+func (q *Query8[A, B, C, D, E, F, G, H]) ParForEach(fn func(cmds *Commands, entity IEntity, a A, b B, c C, d D, e E, f F, g G, h H)) {
+	q.parRangeRows(func(cmds *Commands, entity IEntity, row []IComponent) {
+		fn(cmds, entity, cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]), cast[H](row[7]))
+	})
+}
+
+// Get 获取指定实体的组件，实体不满足查询条件时返回 false
+func (q *Query8[A, B, C, D, E, F, G, H]) Get(entity IEntity) (A, B, C, D, E, F, G, H, bool) {
+	row := make([]IComponent, len(q.ids))
+	if !q.Matches(entity) || !q.fetch(entity, row) {
+		var a A
+		var b B
+		var c C
+		var d D
+		var e E
+		var f F
+		var g G
+		var h H
+		return a, b, c, d, e, f, g, h, false
+	}
+	return cast[A](row[0]), cast[B](row[1]), cast[C](row[2]), cast[D](row[3]), cast[E](row[4]), cast[F](row[5]), cast[G](row[6]), cast[H](row[7]), true
+}
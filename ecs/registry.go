@@ -0,0 +1,426 @@
+package ecs
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// FieldInfo 组件的导出字段
+type FieldInfo struct {
+	Name   string
+	Type   reflect.Type
+	Offset uintptr
+	Tag    reflect.StructTag
+}
+
+// ComponentType 注册到 World 的组件或资源类型
+// Name 在不同进程之间保持稳定，序列化时用它代替与注册顺序有关的组件ID
+type ComponentType struct {
+	Name     string
+	Type     reflect.Type
+	Id       ComponentId
+	Size     uintptr        // 结构体的大小，指针类型为其指向的结构体
+	Fields   []FieldInfo    // 导出字段，不包含嵌入的 Component
+	Meta     map[string]any // 注册时附加的自定义信息
+	Required []reflect.Type // 依赖的组件类型，添加该组件时自动补充缺少的依赖
+	auto     bool           // 第一次使用时自动注册，之后显式注册可以覆盖名称和自定义信息
+	create   func() IComponent
+	newInfo  func() IComponentInfo // 标签和值组件的存储，普通组件为 nil
+	defaults map[reflect.Type]IComponent
+}
+
+type RegisterOption func(c *ComponentType)
+
+// WithTypeName 指定类型名称，默认为包路径加类型名
+func WithTypeName(name string) RegisterOption {
+	return func(c *ComponentType) {
+		c.Name = name
+	}
+}
+
+// ReservedIdStart 从这里开始的ID保留给 Parent、Children 等内置组件，固定ID需要小于它
+const ReservedIdStart uint64 = 1 << 63
+
+const (
+	parentComponentId = ReservedIdStart + iota
+	childrenComponentId
+)
+
+// WithFixedId 指定固定的组件ID，必须在类型第一次使用之前注册
+// 有效范围是 [1, ReservedIdStart)，新创建的 World 中这个范围内的ID都还没有被占用
+func WithFixedId(id uint64) RegisterOption {
+	return func(c *ComponentType) {
+		if id >= ReservedIdStart {
+			panic(fmt.Errorf("ecs: fixed id %d is reserved for built-in components", id))
+		}
+		c.Id = ComponentId(id)
+	}
+}
+
+// withReservedId 内置组件使用保留范围内的ID
+func withReservedId(id uint64) RegisterOption {
+	return func(c *ComponentType) {
+		c.Id = ComponentId(id)
+	}
+}
+
+// WithMeta 附加自定义信息，供编辑器、脚本等工具使用
+func WithMeta(key string, value any) RegisterOption {
+	return func(c *ComponentType) {
+		c.Meta[key] = value
+	}
+}
+
+// WithRequired 声明依赖的组件，添加该组件时实体上缺少的依赖会被自动创建
+// 传入 nil 指针时从组件池中创建默认组件，否则复制传入的组件作为默认值
+//
+//	ecs.RegisterComponent[*RigidBody](w, ecs.WithRequired((*Transform)(nil), &Mass{Value: 1}))
+func WithRequired(components ...IComponent) RegisterOption {
+	return func(c *ComponentType) {
+		for _, component := range components {
+			t := reflect.TypeOf(component)
+			if _, ok := c.defaults[t]; !ok {
+				c.Required = append(c.Required, t)
+			}
+			c.defaults[t] = component
+		}
+	}
+}
+
+func newComponentType(t reflect.Type, create func() IComponent) *ComponentType {
+	c := &ComponentType{
+		Name:     typeName(t),
+		Type:     t,
+		Fields:   make([]FieldInfo, 0),
+		Meta:     make(map[string]any),
+		Required: make([]reflect.Type, 0),
+		create:   create,
+		defaults: make(map[reflect.Type]IComponent),
+	}
+
+	elem := t
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	c.Size = elem.Size()
+	if elem.Kind() != reflect.Struct {
+		return c
+	}
+	componentType := reflect.TypeOf(Component{})
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		if !field.IsExported() || (field.Anonymous && field.Type == componentType) {
+			continue
+		}
+		c.Fields = append(c.Fields, FieldInfo{Name: field.Name, Type: field.Type, Offset: field.Offset, Tag: field.Tag})
+	}
+	return c
+}
+
+// clone 复制类型信息，Meta、Required 等可以被选项修改的字段不与原来的共享
+func (c *ComponentType) clone() *ComponentType {
+	copied := *c
+	copied.Meta = maps.Clone(c.Meta)
+	copied.Required = slices.Clone(c.Required)
+	copied.defaults = maps.Clone(c.defaults)
+	return &copied
+}
+
+// New 创建该类型的一个实例
+func (c *ComponentType) New() IComponent {
+	return c.create()
+}
+
+type typeTable struct {
+	byName map[string]*ComponentType
+	byType map[reflect.Type]*ComponentType
+}
+
+func newTypeTable() *typeTable {
+	return &typeTable{
+		byName: make(map[string]*ComponentType),
+		byType: make(map[reflect.Type]*ComponentType),
+	}
+}
+
+// register 登记类型，名称或固定ID冲突时 panic，此时已有的类型信息保持不变
+func (t *typeTable) register(ig *IdentityGetter, c *ComponentType, opts ...RegisterOption) *ComponentType {
+	existing, ok := t.byType[c.Type]
+	if ok {
+		if !existing.auto || c.auto {
+			// 再次显式注册时合并新声明的依赖组件，名称和固定ID等其他选项不再修改
+			if !c.auto {
+				merged := existing.clone()
+				for _, opt := range opts {
+					opt(merged)
+				}
+				existing.Required = merged.Required
+				existing.defaults = merged.defaults
+			}
+			return existing
+		}
+		// 选项先作用在副本上，校验通过后再写回
+		c = existing.clone()
+		c.auto = false
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if other, ok := t.byName[c.Name]; ok && other.Type != c.Type {
+		panic(fmt.Errorf("ecs: type name %q is already used by %s", c.Name, other.Type))
+	}
+	if c.Id != 0 {
+		if err := ig.SetID(c.Type, uint64(c.Id)); err != nil {
+			panic(err)
+		}
+	} else {
+		c.Id = ComponentId(ig.GetID(c.Type))
+	}
+
+	if existing != nil {
+		delete(t.byName, existing.Name)
+		*existing = *c
+		c = existing
+	}
+	t.byName[c.Name] = c
+	t.byType[c.Type] = c
+	return c
+}
+
+func (t *typeTable) list() []*ComponentType {
+	types := make([]*ComponentType, 0, len(t.byName))
+	for _, c := range t.byName {
+		types = append(types, c)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// Registry 组件和资源的类型注册表
+type Registry struct {
+	mu         sync.RWMutex
+	components *typeTable
+	resources  *typeTable
+	relations  *typeTable
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		components: newTypeTable(),
+		resources:  newTypeTable(),
+		relations:  newTypeTable(),
+	}
+}
+
+// Components 所有注册的组件类型，按名称排序
+func (r *Registry) Components() []*ComponentType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.components.list()
+}
+
+// Resources 所有注册的资源类型，按名称排序
+func (r *Registry) Resources() []*ComponentType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resources.list()
+}
+
+// Component 根据名称查找组件类型
+func (r *Registry) Component(name string) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	componentType, ok := r.components.byName[name]
+	return componentType, ok
+}
+
+func (r *Registry) ComponentOf(t reflect.Type) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	componentType, ok := r.components.byType[t]
+	return componentType, ok
+}
+
+func (r *Registry) Resource(name string) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resourceType, ok := r.resources.byName[name]
+	return resourceType, ok
+}
+
+func (r *Registry) ResourceOf(t reflect.Type) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resourceType, ok := r.resources.byType[t]
+	return resourceType, ok
+}
+
+// Relations 所有注册的关系类型，按名称排序
+func (r *Registry) Relations() []*ComponentType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.relations.list()
+}
+
+func (r *Registry) Relation(name string) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	relationType, ok := r.relations.byName[name]
+	return relationType, ok
+}
+
+func (r *Registry) RelationOf(t reflect.Type) (*ComponentType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	relationType, ok := r.relations.byType[t]
+	return relationType, ok
+}
+
+// typeName 默认的类型名称：包路径加类型名，指针类型使用其指向的类型
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+func (w *World) GetRegistry() *Registry {
+	return w.registry
+}
+
+// RegisterComponent 注册组件类型，重复注册返回已有的类型信息，并合并新声明的依赖组件
+// 类型名称或固定ID与已注册的类型冲突时 panic
+func RegisterComponent[T IComponent](w *World, opts ...RegisterOption) *ComponentType {
+	create := func() IComponent {
+		return SpawnComponent[T](w)
+	}
+	w.registry.mu.Lock()
+	defer w.registry.mu.Unlock()
+	return w.registry.components.register(w.compIdGetter, newComponentType(typeOf[T](), create), opts...)
+}
+
+// RegisterResource 注册资源类型，重复注册返回已有的类型信息
+func RegisterResource[T IComponent](w *World, opts ...RegisterOption) *ComponentType {
+	t := typeOf[T]()
+	create := func() IComponent {
+		if t.Kind() == reflect.Ptr {
+			return reflect.New(t.Elem()).Interface().(IComponent)
+		}
+		return reflect.New(t).Elem().Interface().(IComponent)
+	}
+	w.registry.mu.Lock()
+	defer w.registry.mu.Unlock()
+	return w.registry.resources.register(w.resIdGetter, newComponentType(t, create), opts...)
+}
+
+// RegisterTag 注册标签类型，加载快照之前需要注册快照中出现的标签
+func RegisterTag[T any](w *World, opts ...RegisterOption) *ComponentType {
+	return w.registerSparse(typeOf[T](), newTagInfoFunc, opts...)
+}
+
+// RegisterValue 注册值组件类型，加载快照之前需要注册快照中出现的值组件
+func RegisterValue[T any](w *World, opts ...RegisterOption) *ComponentType {
+	return w.registerSparse(typeOf[T](), newValueInfoFunc[T](), opts...)
+}
+
+// RegisterRelation 注册关系类型，存档中以类型名称标识关系
+func RegisterRelation[R any](w *World, opts ...RegisterOption) *ComponentType {
+	w.registry.mu.Lock()
+	defer w.registry.mu.Unlock()
+	return w.registry.relations.register(w.relationIdGetter, newComponentType(typeOf[R](), nil), opts...)
+}
+
+func newSparseType(t reflect.Type, newInfo func() IComponentInfo) *ComponentType {
+	c := newComponentType(t, func() IComponent {
+		return nil
+	})
+	c.newInfo = newInfo
+	return c
+}
+
+func (w *World) registerSparse(t reflect.Type, newInfo func() IComponentInfo, opts ...RegisterOption) *ComponentType {
+	w.registry.mu.Lock()
+	defer w.registry.mu.Unlock()
+	return w.registry.components.register(w.compIdGetter, newSparseType(t, newInfo), opts...)
+}
+
+// registerAuto 类型第一次使用时自动登记到注册表
+func (w *World) registerAuto(table *typeTable, ig *IdentityGetter, c *ComponentType) {
+	c.auto = true
+	w.registry.mu.Lock()
+	table.register(ig, c)
+	w.registry.mu.Unlock()
+}
+
+// ensureComponent 组件类型第一次出现时自动创建 ComponentInfo 并登记到注册表
+func (w *World) ensureComponent(t reflect.Type) ComponentId {
+	componentId := ComponentId(w.GetCompId(t))
+	if _, ok := w.componentMap[componentId]; !ok {
+		w.componentMap[componentId] = newComponentInfoOf(w, t)
+	}
+	if _, ok := w.registry.ComponentOf(t); !ok {
+		c := newComponentType(t, func() IComponent {
+			return w.componentMap[w.ensureComponent(t)].CreateComponent()
+		})
+		w.registerAuto(w.registry.components, w.compIdGetter, c)
+	}
+	return componentId
+}
+
+// withRequired 在组件列表前补充实体缺少的依赖组件，依赖的依赖先于依赖添加
+// 实体上已有或列表中已有的类型不会重复创建
+func (w *World) withRequired(entity IEntity, components []IComponent) []IComponent {
+	hasRequired := false
+	for _, component := range components {
+		if c, ok := w.registry.ComponentOf(reflect.TypeOf(component)); ok && len(c.Required) > 0 {
+			hasRequired = true
+			break
+		}
+	}
+	if !hasRequired {
+		return components
+	}
+
+	present := make(map[reflect.Type]bool, len(components))
+	for _, component := range components {
+		present[reflect.TypeOf(component)] = true
+	}
+
+	required := make([]IComponent, 0)
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		c, ok := w.registry.ComponentOf(t)
+		if !ok {
+			return
+		}
+		for _, requiredType := range c.Required {
+			if present[requiredType] {
+				continue
+			}
+			present[requiredType] = true
+			if _, ok := w.getComponent(entity, w.ensureComponent(requiredType)); ok {
+				continue
+			}
+			visit(requiredType)
+			if template := c.defaults[requiredType]; reflect.ValueOf(template).IsNil() {
+				required = append(required, w.componentMap[w.ensureComponent(requiredType)].CreateComponent())
+			} else {
+				required = append(required, w.cloneComponent(template))
+			}
+		}
+	}
+	for _, component := range components {
+		visit(reflect.TypeOf(component))
+	}
+	return append(required, components...)
+}
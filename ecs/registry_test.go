@@ -0,0 +1,179 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testAuto struct {
+	Component
+	Value int
+}
+
+type testNamed struct {
+	Component
+	Speed  float64 `json:"speed"`
+	hidden int
+}
+
+func TestRegistry_Options(t *testing.T) {
+	w := NewWorld()
+	named := RegisterComponent[*testNamed](w, WithTypeName("game.Named"), WithFixedId(100), WithMeta("editor", "hidden"))
+
+	if named.Id != 100 || SpawnComponent[*testNamed](w).ID() != 100 {
+		t.Fatalf("fixed id should be used, got %d", named.Id)
+	}
+	if c, ok := w.GetRegistry().Component("game.Named"); !ok || c.Type != reflect.TypeOf(&testNamed{}) {
+		t.Fatalf("type should be found by name")
+	}
+	if named.Meta["editor"] != "hidden" {
+		t.Fatalf("meta should be kept")
+	}
+	if len(named.Fields) != 1 || named.Fields[0].Name != "Speed" || named.Fields[0].Tag.Get("json") != "speed" {
+		t.Fatalf("only exported fields should be listed, got %+v", named.Fields)
+	}
+	if named.Size != reflect.TypeOf(testNamed{}).Size() {
+		t.Fatalf("unexpected size %d", named.Size)
+	}
+
+	// 固定ID冲突时 panic
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("conflicting fixed id should panic")
+		}
+	}()
+	RegisterComponent[*testAuto](w, WithFixedId(100))
+}
+
+func TestRegistry_LowFixedId(t *testing.T) {
+	// 内置组件使用保留的ID，新的 World 中较小的固定ID都可以使用
+	w := NewWorld()
+	if c := RegisterComponent[*testNamed](w, WithFixedId(1)); c.Id != 1 || SpawnComponent[*testNamed](w).ID() != 1 {
+		t.Fatalf("fixed id 1 should be available")
+	}
+	RegisterComponent[*testAuto](w, WithFixedId(2))
+	if parent, _ := w.GetRegistry().ComponentOf(typeOf[*Parent]()); uint64(parent.Id) < ReservedIdStart {
+		t.Fatalf("built-in components should use reserved ids, got %d", parent.Id)
+	}
+	if id := SpawnComponent[*testFrozen](w).ID(); id == 1 || id == 2 {
+		t.Fatalf("automatic ids should skip fixed ids, got %d", id)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("reserved fixed id should panic")
+		}
+	}()
+	RegisterComponent[*testVelocity](w, WithFixedId(ReservedIdStart))
+}
+
+func TestRegistry_AutoRegister(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			// 没有通过 SpawnComponent 创建过的组件也能被添加
+			entity := SpawnEmptyEntity(w, &testAuto{Value: 1})
+			if c := GetComponent[*testAuto](entity); c == nil || c.Value != 1 {
+				t.Fatalf("component should be attached")
+			}
+			if n := NewQuery1[*testAuto](w).Count(); n != 1 {
+				t.Fatalf("expected 1 entity, got %d", n)
+			}
+
+			other := SpawnEmptyEntity(w)
+			other.AddComponents(&testVelocity{X: 2})
+			if c := GetComponent[*testVelocity](other); c == nil || c.X != 2 {
+				t.Fatalf("component should be attached")
+			}
+
+			// 自动注册之后仍然可以显式指定名称
+			auto, ok := w.GetRegistry().ComponentOf(reflect.TypeOf(&testAuto{}))
+			if !ok || auto.Name != typeName(reflect.TypeOf(&testAuto{})) {
+				t.Fatalf("component should be registered automatically")
+			}
+			RegisterComponent[*testAuto](w, WithTypeName("game.Auto"))
+			if _, ok := w.GetRegistry().Component("game.Auto"); !ok {
+				t.Fatalf("explicit registration should rename the type")
+			}
+			if created := SpawnComponent[*testAuto](w); created.ID() != uint64(auto.Id) {
+				t.Fatalf("component id should not change")
+			}
+		})
+	}
+}
+
+func TestRegistry_FailedRegisterKeepsType(t *testing.T) {
+	w := NewWorld()
+	RegisterComponent[*testNamed](w, WithTypeName("game.Named"), WithFixedId(100))
+	SpawnEmptyEntity(w, &testAuto{})
+	auto, _ := w.GetRegistry().ComponentOf(reflect.TypeOf(&testAuto{}))
+	name, id := auto.Name, auto.Id
+
+	mustPanic := func(opts ...RegisterOption) {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("conflicting registration should panic")
+			}
+		}()
+		RegisterComponent[*testAuto](w, opts...)
+	}
+	mustPanic(WithTypeName("game.Auto"), WithMeta("editor", "hidden"), WithFixedId(100))
+	mustPanic(WithTypeName("game.Named"))
+
+	// 失败的注册不会修改自动注册的类型信息
+	if auto.Name != name || auto.Id != id || len(auto.Meta) != 0 || !auto.auto {
+		t.Fatalf("failed registration should not mutate the type, got %+v", auto)
+	}
+	if c, ok := w.GetRegistry().Component(name); !ok || c != auto {
+		t.Fatalf("type should still be found by its name")
+	}
+	if _, ok := w.GetRegistry().Component("game.Auto"); ok {
+		t.Fatalf("failed name should not be registered")
+	}
+
+	// 之后仍然可以正常注册
+	if c := RegisterComponent[*testAuto](w, WithTypeName("game.Auto")); c != auto || c.Name != "game.Auto" || c.Id != id {
+		t.Fatalf("registration after a failure should update the type")
+	}
+}
+
+type testRigidBody struct {
+	Component
+}
+
+type testCollider struct {
+	Component
+}
+
+func TestRegistry_Required(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			RegisterComponent[*testCollider](w, WithRequired((*testVelocity)(nil)))
+			RegisterComponent[*testRigidBody](w, WithRequired((*testPosition)(nil), &testHealth{Value: 5}, (*testCollider)(nil)))
+
+			a := SpawnEmptyEntity(w, &testRigidBody{})
+			if GetComponent[*testPosition](a) == nil || GetComponent[*testCollider](a) == nil {
+				t.Fatalf("required components should be inserted")
+			}
+			if GetComponent[*testVelocity](a) == nil {
+				t.Fatalf("requirements of required components should be inserted")
+			}
+			if h := GetComponent[*testHealth](a); h == nil || h.Value != 5 {
+				t.Fatalf("required component should copy the default value")
+			}
+
+			// 再次注册时合并新的依赖
+			RegisterComponent[*testCollider](w, WithRequired(&testFrozen{}))
+			c := SpawnEmptyEntity(w, &testCollider{})
+			if GetComponent[*testVelocity](c) == nil || GetComponent[*testFrozen](c) == nil {
+				t.Fatalf("requirements should be merged when registering again")
+			}
+
+			// 实体上已有或同时添加的依赖保持不变
+			b := SpawnEmptyEntity(w, &testHealth{Value: 1})
+			b.AddComponents(&testRigidBody{}, &testPosition{X: 3})
+			if GetComponent[*testHealth](b).Value != 1 || GetComponent[*testPosition](b).X != 3 {
+				t.Fatalf("existing components should not be replaced by requirements")
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/INT-Game/go-ecs/sparse_set"
+)
+
+// Wildcard 关系过滤中表示任意目标
+const Wildcard EntityId = 0
+
+type pairKey struct {
+	relation uint64
+	target   EntityId
+}
+
+// Relations 实体之间的关系对 (relation, target)，关系类型用 Go 类型区分，例如 (Likes, enemy)
+// 关系不是组件，不会改变实体所在的原型；目标实体被销毁时指向它的关系会被自动清理
+type Relations struct {
+	mu       sync.RWMutex
+	sources  map[pairKey]*sparse_set.SparseSet[uint64] // 拥有该关系对的实体索引，target 为 Wildcard 时表示指向任意目标
+	targets  map[EntityId]map[uint64][]EntityId        // 实体 -> 关系 -> 目标，按添加顺序
+	incoming map[EntityId]map[uint64]struct{}          // 目标 -> 指向它的关系
+}
+
+func NewRelations() *Relations {
+	return &Relations{
+		sources:  make(map[pairKey]*sparse_set.SparseSet[uint64]),
+		targets:  make(map[EntityId]map[uint64][]EntityId),
+		incoming: make(map[EntityId]map[uint64]struct{}),
+	}
+}
+
+func (r *Relations) addSource(key pairKey, entityId EntityId) {
+	set, ok := r.sources[key]
+	if !ok {
+		set = sparse_set.NewSparseSet[uint64](32)
+		r.sources[key] = set
+	}
+	set.Add(uint64(entityId.Index()))
+}
+
+func (r *Relations) removeSource(key pairKey, entityId EntityId) {
+	set, ok := r.sources[key]
+	if !ok {
+		return
+	}
+	set.Remove(uint64(entityId.Index()))
+	if set.Len() == 0 {
+		delete(r.sources, key)
+	}
+}
+
+func (r *Relations) add(entityId EntityId, relation uint64, target EntityId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	relations, ok := r.targets[entityId]
+	if !ok {
+		relations = make(map[uint64][]EntityId)
+		r.targets[entityId] = relations
+	}
+	for _, t := range relations[relation] {
+		if t == target {
+			return
+		}
+	}
+	relations[relation] = append(relations[relation], target)
+
+	r.addSource(pairKey{relation, target}, entityId)
+	r.addSource(pairKey{relation, Wildcard}, entityId)
+
+	if _, ok = r.incoming[target]; !ok {
+		r.incoming[target] = make(map[uint64]struct{})
+	}
+	r.incoming[target][relation] = struct{}{}
+}
+
+func (r *Relations) remove(entityId EntityId, relation uint64, target EntityId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.doRemove(entityId, relation, target)
+}
+
+func (r *Relations) doRemove(entityId EntityId, relation uint64, target EntityId) {
+	relations := r.targets[entityId]
+	targets := relations[relation]
+	for i, t := range targets {
+		if t != target {
+			continue
+		}
+		targets = append(targets[:i], targets[i+1:]...)
+		r.removeSource(pairKey{relation, target}, entityId)
+		if len(targets) == 0 {
+			delete(relations, relation)
+			r.removeSource(pairKey{relation, Wildcard}, entityId)
+		} else {
+			relations[relation] = targets
+		}
+		if len(relations) == 0 {
+			delete(r.targets, entityId)
+		}
+		if _, ok := r.sources[pairKey{relation, target}]; !ok {
+			delete(r.incoming[target], relation)
+			if len(r.incoming[target]) == 0 {
+				delete(r.incoming, target)
+			}
+		}
+		return
+	}
+}
+
+// pairsOf 实体的所有关系对，关系 -> 目标，目标按添加顺序
+func (r *Relations) pairsOf(entityId EntityId) map[uint64][]EntityId {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pairs := make(map[uint64][]EntityId, len(r.targets[entityId]))
+	for relation, targets := range r.targets[entityId] {
+		pairs[relation] = append([]EntityId(nil), targets...)
+	}
+	return pairs
+}
+
+// has 稀疏集中只有索引，再用完整的实体ID检查 targets，槽位复用后旧的句柄不会得到新实体的关系
+func (r *Relations) has(entityId EntityId, relation uint64, target EntityId) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set, ok := r.sources[pairKey{relation, target}]
+	if !ok || !set.Contains(uint64(entityId.Index())) {
+		return false
+	}
+	_, ok = r.targets[entityId][relation]
+	return ok
+}
+
+func (r *Relations) targetsOf(entityId EntityId, relation uint64) []EntityId {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	targets := r.targets[entityId][relation]
+	result := make([]EntityId, len(targets))
+	copy(result, targets)
+	return result
+}
+
+// density 拥有关系对的实体索引的副本
+func (r *Relations) density(key pairKey) []uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set, ok := r.sources[key]
+	if !ok {
+		return make([]uint64, 0)
+	}
+	density := set.Density()
+	result := make([]uint64, len(density))
+	copy(result, density)
+	return result
+}
+
+// removeEntity 实体被销毁时清理它拥有的关系，以及其他实体指向它的关系
+func (r *Relations) removeEntity(w *World, entityId EntityId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for relation, targets := range r.targets[entityId] {
+		for _, target := range append([]EntityId(nil), targets...) {
+			r.doRemove(entityId, relation, target)
+		}
+	}
+
+	for relation := range r.incoming[entityId] {
+		set, ok := r.sources[pairKey{relation, entityId}]
+		if !ok {
+			continue
+		}
+		for _, index := range append([]uint64(nil), set.Density()...) {
+			if source, ok := w.entityAt(uint32(index)); ok {
+				r.doRemove(EntityId(source.ID()), relation, entityId)
+			}
+		}
+	}
+	delete(r.incoming, entityId)
+}
+
+func (w *World) GetRelationId(t reflect.Type) uint64 {
+	if _, ok := w.registry.RelationOf(t); !ok {
+		w.registerAuto(w.registry.relations, w.relationIdGetter, newComponentType(t, nil))
+	}
+	return w.relationIdGetter.GetID(t)
+}
+
+func relationId[R any](w IWorld) uint64 {
+	return w.GetRelationId(typeOf[R]())
+}
+
+// AddRelation 为实体添加关系对 (R, target)，同一个关系可以指向多个目标
+func AddRelation[R any](w *World, entity IEntity, target IEntity) {
+	if !w.IsAlive(entity) || !w.IsAlive(target) {
+		return
+	}
+	w.relations.add(EntityId(entity.ID()), relationId[R](w), EntityId(target.ID()))
+}
+
+// RemoveRelation 移除实体的关系对 (R, target)
+func RemoveRelation[R any](w *World, entity IEntity, target IEntity) {
+	w.relations.remove(EntityId(entity.ID()), relationId[R](w), EntityId(target.ID()))
+}
+
+// HasRelation 判断实体是否拥有关系对 (R, target)，target 为 nil 时表示任意目标
+func HasRelation[R any](w *World, entity IEntity, target IEntity) bool {
+	targetId := Wildcard
+	if target != nil {
+		targetId = EntityId(target.ID())
+	}
+	return w.relations.has(EntityId(entity.ID()), relationId[R](w), targetId)
+}
+
+// Targets 实体通过关系 R 指向的所有目标，按添加顺序排列
+func Targets[R any](w *World, entity IEntity) []IEntity {
+	result := make([]IEntity, 0)
+	for _, targetId := range w.relations.targetsOf(EntityId(entity.ID()), relationId[R](w)) {
+		if target, ok := w.GetEntity(targetId); ok {
+			result = append(result, target)
+		}
+	}
+	return result
+}
+
+// Sources 通过关系 R 指向目标的所有实体
+func Sources[R any](w *World, target IEntity) []IEntity {
+	result := make([]IEntity, 0)
+	for _, index := range w.relations.density(pairKey{relationId[R](w), EntityId(target.ID())}) {
+		if source, ok := w.entityAt(uint32(index)); ok {
+			result = append(result, source)
+		}
+	}
+	return result
+}
+
+// Related 实体必须拥有关系对 (R, target)，target 为 nil 时表示指向任意目标
+func Related[R any](target IEntity) Term {
+	targetId := Wildcard
+	if target != nil {
+		targetId = EntityId(target.ID())
+	}
+	return Term{kind: termPair, types: []reflect.Type{typeOf[R]()}, target: targetId}
+}
+
+// matchPairs 检查关系条件，关系不属于原型，原型存储模式下也需要逐个实体检查
+func (f *Filter) matchPairs(w *World, entityId EntityId) bool {
+	for _, pair := range f.pairs {
+		if !w.relations.has(entityId, pair.relation, pair.target) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"testing"
+)
+
+type testLikes struct{}
+
+type testOwnedBy struct{}
+
+func TestRelation_AddAndQuery(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			alice := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			bob := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			carol := SpawnEmptyEntity(w)
+			sword := SpawnEmptyEntity(w)
+
+			AddRelation[testLikes](w, alice, bob)
+			AddRelation[testLikes](w, alice, carol)
+			AddRelation[testLikes](w, carol, bob)
+			AddRelation[testOwnedBy](w, sword, alice)
+
+			if got := entityIds(Targets[testLikes](w, alice)); len(got) != 2 || got[0] != bob.ID() || got[1] != carol.ID() {
+				t.Fatalf("alice should like bob and carol, got %v", got)
+			}
+			if got := Sources[testLikes](w, bob); len(got) != 2 {
+				t.Fatalf("bob should be liked by 2 entities, got %d", len(got))
+			}
+			if !HasRelation[testLikes](w, carol, nil) || HasRelation[testLikes](w, bob, nil) {
+				t.Fatalf("wildcard relation check failed")
+			}
+
+			// 关系可以和组件条件组合
+			if got := w.GetQuery().Filter(With(&testPosition{}), Related[testLikes](bob)); len(got) != 1 || got[0].ID() != alice.ID() {
+				t.Fatalf("expected only alice, got %v", entityIds(got))
+			}
+			if got := w.GetQuery().Filter(Related[testLikes](nil)); len(got) != 2 {
+				t.Fatalf("expected 2 entities liking anything, got %d", len(got))
+			}
+			if got := NewQuery1[*testPosition](w, Related[testLikes](carol)).Count(); got != 1 {
+				t.Fatalf("expected 1 entity liking carol, got %d", got)
+			}
+
+			RemoveRelation[testLikes](w, alice, carol)
+			if HasRelation[testLikes](w, alice, carol) || !HasRelation[testLikes](w, alice, bob) {
+				t.Fatalf("only the removed pair should be gone")
+			}
+		})
+	}
+}
+
+func TestRelation_CleanupOnDestroy(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			alice := SpawnEmptyEntity(w)
+			bob := SpawnEmptyEntity(w)
+			sword := SpawnEmptyEntity(w)
+			AddRelation[testLikes](w, alice, bob)
+			AddRelation[testOwnedBy](w, sword, alice)
+
+			w.GetCommands().DestroyEntity(alice)
+			w.GetCommands().Execute()
+
+			if len(Sources[testLikes](w, bob)) != 0 {
+				t.Fatalf("relations owned by a destroyed entity should be removed")
+			}
+			if HasRelation[testOwnedBy](w, sword, nil) {
+				t.Fatalf("relations targeting a destroyed entity should be removed")
+			}
+
+			// 复用的实体槽位不会继承旧的关系
+			reused := SpawnEmptyEntity(w)
+			if EntityId(reused.ID()).Index() != EntityId(alice.ID()).Index() {
+				t.Skip("slot was not reused")
+			}
+			if HasRelation[testLikes](w, reused, nil) {
+				t.Fatalf("reused slot should not have relations")
+			}
+		})
+	}
+}
+
+func TestRelation_StaleHandle(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			alice := SpawnEmptyEntity(w)
+			bob := SpawnEmptyEntity(w)
+			w.GetCommands().DestroyEntity(alice).Execute()
+
+			// 新实体复用 alice 的槽位，旧的句柄不能得到新实体的关系
+			dave := SpawnEmptyEntity(w)
+			if EntityId(dave.ID()).Index() != EntityId(alice.ID()).Index() {
+				t.Fatalf("slot should be reused")
+			}
+			AddRelation[testLikes](w, dave, bob)
+			if HasRelation[testLikes](w, alice, bob) || HasRelation[testLikes](w, alice, nil) {
+				t.Fatalf("stale handle should not have the new occupant's relations")
+			}
+			if len(Targets[testLikes](w, alice)) != 0 {
+				t.Fatalf("stale handle should have no targets")
+			}
+			if !HasRelation[testLikes](w, dave, bob) {
+				t.Fatalf("new occupant should keep its relation")
+			}
+		})
+	}
+}
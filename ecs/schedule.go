@@ -0,0 +1,296 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Stage string
+
+const (
+	StageFirst      Stage = "First"
+	StagePreUpdate  Stage = "PreUpdate"
+	StageUpdate     Stage = "Update"
+	StagePostUpdate Stage = "PostUpdate"
+	StageLast       Stage = "Last"
+)
+
+type SystemLabel string
+
+type systemNode struct {
+	system ISystem
+	labels []SystemLabel
+	before []SystemLabel
+	after  []SystemLabel
+}
+
+func (n *systemNode) name() string {
+	return systemName(n.system)
+}
+
+type SystemOption func(node *systemNode)
+
+// Label 为系统添加标签，多个系统可以共用同一个标签
+func Label(labels ...SystemLabel) SystemOption {
+	return func(node *systemNode) {
+		node.labels = append(node.labels, labels...)
+	}
+}
+
+// Before 系统在带有指定标签的所有系统之前运行
+func Before(labels ...SystemLabel) SystemOption {
+	return func(node *systemNode) {
+		node.before = append(node.before, labels...)
+	}
+}
+
+// After 系统在带有指定标签的所有系统之后运行
+func After(labels ...SystemLabel) SystemOption {
+	return func(node *systemNode) {
+		node.after = append(node.after, labels...)
+	}
+}
+
+// Schedule 按阶段组织系统，阶段内根据 Before / After 约束做拓扑排序
+type Schedule struct {
+	stages  []Stage
+	nodes   map[Stage][]*systemNode
+	ordered map[Stage][]*systemNode
+	deps    map[Stage][][]int // 排序后每个系统通过 Before / After 依赖的系统下标
+	dirty   bool
+}
+
+func NewSchedule() *Schedule {
+	s := &Schedule{
+		stages:  make([]Stage, 0),
+		nodes:   make(map[Stage][]*systemNode),
+		ordered: make(map[Stage][]*systemNode),
+		deps:    make(map[Stage][][]int),
+	}
+	for _, stage := range []Stage{StageFirst, StagePreUpdate, StageUpdate, StagePostUpdate, StageLast} {
+		s.AddStage(stage)
+	}
+	return s
+}
+
+func (s *Schedule) Stages() []Stage {
+	return s.stages
+}
+
+func (s *Schedule) hasStage(stage Stage) bool {
+	for _, st := range s.stages {
+		if st == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// AddStage 在最后追加一个阶段
+func (s *Schedule) AddStage(stage Stage) {
+	if s.hasStage(stage) {
+		return
+	}
+	s.stages = append(s.stages, stage)
+	s.dirty = true
+}
+
+// AddStageBefore 在指定阶段之前插入一个阶段
+func (s *Schedule) AddStageBefore(stage Stage, target Stage) {
+	s.insertStage(stage, target, 0)
+}
+
+// AddStageAfter 在指定阶段之后插入一个阶段
+func (s *Schedule) AddStageAfter(stage Stage, target Stage) {
+	s.insertStage(stage, target, 1)
+}
+
+func (s *Schedule) insertStage(stage Stage, target Stage, offset int) {
+	if s.hasStage(stage) {
+		return
+	}
+	for i, st := range s.stages {
+		if st == target {
+			i += offset
+			s.stages = append(s.stages[:i], append([]Stage{stage}, s.stages[i:]...)...)
+			s.dirty = true
+			return
+		}
+	}
+	s.AddStage(stage)
+}
+
+func (s *Schedule) AddSystem(stage Stage, system ISystem, opts ...SystemOption) {
+	s.AddStage(stage)
+	node := &systemNode{
+		system: system,
+		labels: make([]SystemLabel, 0),
+		before: make([]SystemLabel, 0),
+		after:  make([]SystemLabel, 0),
+	}
+	for _, opt := range opts {
+		opt(node)
+	}
+	s.nodes[stage] = append(s.nodes[stage], node)
+	s.dirty = true
+}
+
+// Build 对每个阶段的系统做拓扑排序，存在未知标签或循环依赖时返回错误
+func (s *Schedule) Build() error {
+	if !s.dirty {
+		return nil
+	}
+
+	ordered := make(map[Stage][]*systemNode, len(s.stages))
+	deps := make(map[Stage][][]int, len(s.stages))
+	for _, stage := range s.stages {
+		nodes, stageDeps, err := sortStage(stage, s.nodes[stage])
+		if err != nil {
+			return err
+		}
+		ordered[stage] = nodes
+		deps[stage] = stageDeps
+	}
+
+	s.ordered = ordered
+	s.deps = deps
+	s.dirty = false
+	return nil
+}
+
+// Systems 返回阶段内排序后的系统，需要先调用 Build
+func (s *Schedule) Systems(stage Stage) []ISystem {
+	systems := make([]ISystem, 0, len(s.ordered[stage]))
+	for _, node := range s.ordered[stage] {
+		systems = append(systems, node.system)
+	}
+	return systems
+}
+
+func sortStage(stage Stage, nodes []*systemNode) ([]*systemNode, [][]int, error) {
+	byLabel := make(map[SystemLabel][]int)
+	for i, node := range nodes {
+		for _, label := range node.labels {
+			byLabel[label] = append(byLabel[label], i)
+		}
+	}
+
+	// edges[i] 中的系统必须在 i 之后运行
+	edges := make([][]int, len(nodes))
+	inDegree := make([]int, len(nodes))
+	addEdge := func(from, to int) {
+		if from == to {
+			return
+		}
+		edges[from] = append(edges[from], to)
+		inDegree[to]++
+	}
+
+	for i, node := range nodes {
+		for _, label := range node.before {
+			targets, ok := byLabel[label]
+			if !ok {
+				return nil, nil, fmt.Errorf("ecs: system %s in stage %s runs before unknown label %q", node.name(), stage, label)
+			}
+			for _, j := range targets {
+				addEdge(i, j)
+			}
+		}
+		for _, label := range node.after {
+			targets, ok := byLabel[label]
+			if !ok {
+				return nil, nil, fmt.Errorf("ecs: system %s in stage %s runs after unknown label %q", node.name(), stage, label)
+			}
+			for _, j := range targets {
+				addEdge(j, i)
+			}
+		}
+	}
+
+	// Kahn 算法，入度相同时按添加顺序，保证结果稳定
+	ready := make([]int, 0)
+	for i := range nodes {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	sorted := make([]*systemNode, 0, len(nodes))
+	position := make([]int, len(nodes))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		position[i] = len(sorted)
+		sorted = append(sorted, nodes[i])
+		for _, j := range edges[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	if len(sorted) != len(nodes) {
+		return nil, nil, fmt.Errorf("ecs: cycle detected in stage %s: %s", stage, describeCycle(nodes, edges, inDegree))
+	}
+
+	deps := make([][]int, len(sorted))
+	for from, tos := range edges {
+		for _, to := range tos {
+			deps[position[to]] = append(deps[position[to]], position[from])
+		}
+	}
+	return sorted, deps, nil
+}
+
+// describeCycle 从剩余的节点中找出一个环用于错误信息
+// 剩余节点的入度都大于 0，沿着仍未排序的前驱一直回溯必然会回到走过的节点
+func describeCycle(nodes []*systemNode, edges [][]int, inDegree []int) string {
+	preds := make([][]int, len(nodes))
+	for from, tos := range edges {
+		for _, to := range tos {
+			preds[to] = append(preds[to], from)
+		}
+	}
+
+	current := -1
+	for i := range nodes {
+		if inDegree[i] > 0 {
+			current = i
+			break
+		}
+	}
+
+	visited := make(map[int]int)
+	path := make([]int, 0)
+	for current >= 0 {
+		if pos, ok := visited[current]; ok {
+			cycle := path[pos:]
+			names := make([]string, 0, len(cycle)+1)
+			for i := len(cycle) - 1; i >= 0; i-- {
+				names = append(names, nodes[cycle[i]].name())
+			}
+			names = append(names, names[0])
+			return strings.Join(names, " -> ")
+		}
+		visited[current] = len(path)
+		path = append(path, current)
+
+		next := -1
+		for _, j := range preds[current] {
+			if inDegree[j] > 0 {
+				next = j
+				break
+			}
+		}
+		current = next
+	}
+	return ""
+}
+
+func systemName(system ISystem) string {
+	return reflect.TypeOf(system).String()
+}
@@ -0,0 +1,64 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+)
+
+type testOrderSystem struct {
+	System
+	name string
+	log  *[]string
+}
+
+func newTestOrderSystem(w *World, name string, log *[]string) *testOrderSystem {
+	return &testOrderSystem{System: *NewSystem(w), name: name, log: log}
+}
+
+func (s *testOrderSystem) Update() {
+	*s.log = append(*s.log, s.name)
+}
+
+func TestSchedule_StagesAndOrdering(t *testing.T) {
+	w := NewWorld()
+	log := make([]string, 0)
+
+	w.AddSystem(StagePostUpdate, newTestOrderSystem(w, "render", &log))
+	w.AddUpdateSystem(newTestOrderSystem(w, "physics", &log), Label("physics"), After("input"))
+	w.AddUpdateSystem(newTestOrderSystem(w, "input", &log), Label("input"))
+	w.AddUpdateSystem(newTestOrderSystem(w, "ai", &log), Before("physics"))
+	w.AddSystem(StageFirst, newTestOrderSystem(w, "time", &log))
+
+	if err := w.BuildSchedule(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	w.Update()
+
+	expected := "time,input,ai,physics,render"
+	if got := strings.Join(log, ","); got != expected {
+		t.Fatalf("expected order %s, got %s", expected, got)
+	}
+}
+
+func TestSchedule_Cycle(t *testing.T) {
+	w := NewWorld()
+	log := make([]string, 0)
+
+	w.AddUpdateSystem(newTestOrderSystem(w, "a", &log), Label("a"), After("b"))
+	w.AddUpdateSystem(newTestOrderSystem(w, "b", &log), Label("b"), After("a"))
+
+	err := w.BuildSchedule()
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestSchedule_UnknownLabel(t *testing.T) {
+	w := NewWorld()
+	log := make([]string, 0)
+
+	w.AddUpdateSystem(newTestOrderSystem(w, "a", &log), After("missing"))
+	if err := w.BuildSchedule(); err == nil {
+		t.Fatalf("expected unknown label error")
+	}
+}
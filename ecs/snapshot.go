@@ -0,0 +1,378 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+type Format int
+
+const (
+	// FormatJSON 便于调试和比较的文本格式
+	FormatJSON Format = iota
+	// FormatBinary 基于 gob 的紧凑二进制格式，适合存档
+	FormatBinary
+)
+
+// snapshotVersion 版本 2 增加了标签、值组件和关系，版本 1 的快照仍然可以加载
+const snapshotVersion = 2
+
+// binaryMagic 二进制快照的文件头，Load 根据它识别格式
+var binaryMagic = []byte("GOECS\x00")
+
+type componentSnapshot struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type relationSnapshot struct {
+	Type   string   `json:"type"`
+	Target EntityId `json:"target"`
+}
+
+type entitySnapshot struct {
+	Id         EntityId            `json:"id"`
+	Components []componentSnapshot `json:"components"`
+	Tags       []string            `json:"tags,omitempty"`
+	Values     []componentSnapshot `json:"values,omitempty"`
+	Relations  []relationSnapshot  `json:"relations,omitempty"`
+}
+
+type worldSnapshot struct {
+	Version   int                 `json:"version"`
+	Entities  []entitySnapshot    `json:"entities"`
+	Resources []componentSnapshot `json:"resources"`
+}
+
+// sparseCodec 标签和值组件按实体序列化，标签没有数据
+// decodeEntity 只解码数据，返回的函数在实体加入稀疏集之后写入值
+type sparseCodec interface {
+	encodeEntity(format Format, entityId EntityId) ([]byte, error)
+	decodeEntity(format Format, data []byte) (func(componentInfo IComponentInfo, entityId EntityId), error)
+}
+
+func encodeValue(format Format, value any) ([]byte, error) {
+	if format == FormatJSON {
+		return json.Marshal(value)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeValue(format Format, data []byte, value any) error {
+	if format == FormatJSON {
+		return json.Unmarshal(data, value)
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// Save 将所有实体、组件、标签、值组件、关系和资源写入 writer，实体ID保持不变
+// 组件、标签、值组件和关系的类型在第一次使用时自动注册，未注册的资源会被跳过
+func (w *World) Save(writer io.Writer, format Format) error {
+	snapshot := worldSnapshot{
+		Version:   snapshotVersion,
+		Entities:  make([]entitySnapshot, 0, len(w.entities)),
+		Resources: make([]componentSnapshot, 0),
+	}
+
+	// 注册表按名称排序，标签和值组件按名称的顺序写入
+	sparseTypes := make([]*ComponentType, 0)
+	for _, componentType := range w.registry.Components() {
+		if _, ok := w.sparseOnly[componentType.Id]; ok && componentType.newInfo != nil {
+			sparseTypes = append(sparseTypes, componentType)
+		}
+	}
+	relationNames := make(map[uint64]string)
+	for _, relationType := range w.registry.Relations() {
+		relationNames[uint64(relationType.Id)] = relationType.Name
+	}
+
+	for entityId, entity := range w.entities {
+		entitySnap := entitySnapshot{Id: entityId, Components: make([]componentSnapshot, 0)}
+		var err error
+		w.rangeComponents(entity, func(componentId ComponentId, component IComponent) {
+			if err != nil {
+				return
+			}
+			componentType, ok := w.registry.ComponentOf(reflect.TypeOf(component))
+			if !ok {
+				err = fmt.Errorf("ecs: component type %s is not registered", reflect.TypeOf(component))
+				return
+			}
+			var data []byte
+			if data, err = encodeValue(format, component); err != nil {
+				err = fmt.Errorf("ecs: encode component %s: %w", componentType.Name, err)
+				return
+			}
+			entitySnap.Components = append(entitySnap.Components, componentSnapshot{Type: componentType.Name, Data: data})
+		})
+		if err != nil {
+			return err
+		}
+		sort.Slice(entitySnap.Components, func(i, j int) bool {
+			return entitySnap.Components[i].Type < entitySnap.Components[j].Type
+		})
+		if err = w.saveSparse(&entitySnap, format, sparseTypes); err != nil {
+			return err
+		}
+		if err = w.saveRelations(&entitySnap, relationNames); err != nil {
+			return err
+		}
+		snapshot.Entities = append(snapshot.Entities, entitySnap)
+	}
+	sort.Slice(snapshot.Entities, func(i, j int) bool {
+		return snapshot.Entities[i].Id < snapshot.Entities[j].Id
+	})
+
+	for _, resourceInfo := range w.resourceMap {
+		if resourceInfo.resource == nil {
+			continue
+		}
+		resourceType, ok := w.registry.ResourceOf(reflect.TypeOf(resourceInfo.resource))
+		if !ok {
+			continue
+		}
+		data, err := encodeValue(format, resourceInfo.resource)
+		if err != nil {
+			return fmt.Errorf("ecs: encode resource %s: %w", resourceType.Name, err)
+		}
+		snapshot.Resources = append(snapshot.Resources, componentSnapshot{Type: resourceType.Name, Data: data})
+	}
+	sort.Slice(snapshot.Resources, func(i, j int) bool {
+		return snapshot.Resources[i].Type < snapshot.Resources[j].Type
+	})
+
+	if format == FormatJSON {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(snapshot)
+	}
+	if _, err := writer.Write(binaryMagic); err != nil {
+		return err
+	}
+	return gob.NewEncoder(writer).Encode(snapshot)
+}
+
+// saveSparse 写入实体的标签和值组件
+func (w *World) saveSparse(entitySnap *entitySnapshot, format Format, sparseTypes []*ComponentType) error {
+	for _, componentType := range sparseTypes {
+		componentInfo := w.componentMap[componentType.Id]
+		if !componentInfo.Contains(entitySnap.Id) {
+			continue
+		}
+		if _, ok := componentInfo.(*tagInfo); ok {
+			entitySnap.Tags = append(entitySnap.Tags, componentType.Name)
+			continue
+		}
+		data, err := componentInfo.(sparseCodec).encodeEntity(format, entitySnap.Id)
+		if err != nil {
+			return fmt.Errorf("ecs: encode value component %s: %w", componentType.Name, err)
+		}
+		entitySnap.Values = append(entitySnap.Values, componentSnapshot{Type: componentType.Name, Data: data})
+	}
+	return nil
+}
+
+// saveRelations 写入实体的关系对，按关系名称排序，同一关系的目标保持添加顺序
+func (w *World) saveRelations(entitySnap *entitySnapshot, relationNames map[uint64]string) error {
+	for relation, targets := range w.relations.pairsOf(entitySnap.Id) {
+		name, ok := relationNames[relation]
+		if !ok {
+			return fmt.Errorf("ecs: relation type %d is not registered", relation)
+		}
+		for _, target := range targets {
+			entitySnap.Relations = append(entitySnap.Relations, relationSnapshot{Type: name, Target: target})
+		}
+	}
+	sort.SliceStable(entitySnap.Relations, func(i, j int) bool {
+		return entitySnap.Relations[i].Type < entitySnap.Relations[j].Type
+	})
+	return nil
+}
+
+type loadedSparse struct {
+	componentType *ComponentType
+	apply         func(componentInfo IComponentInfo, entityId EntityId)
+}
+
+type loadedRelation struct {
+	relation uint64
+	target   EntityId
+}
+
+type loadedEntity struct {
+	id         EntityId
+	components []IComponent
+	sparse     []loadedSparse
+	relations  []loadedRelation
+}
+
+// Load 用 reader 中的快照替换 World 中的所有实体，并设置快照中的资源，格式自动识别
+// 快照中的实体以原来的ID重新创建；出现未注册的类型或数据错误时 World 保持不变
+func (w *World) Load(reader io.Reader) error {
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	format := FormatJSON
+	var snapshot worldSnapshot
+	if bytes.HasPrefix(raw, binaryMagic) {
+		format = FormatBinary
+		err = gob.NewDecoder(bytes.NewReader(raw[len(binaryMagic):])).Decode(&snapshot)
+	} else {
+		err = json.Unmarshal(raw, &snapshot)
+	}
+	if err != nil {
+		return fmt.Errorf("ecs: decode snapshot: %w", err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > snapshotVersion {
+		return fmt.Errorf("ecs: unsupported snapshot version %d", snapshot.Version)
+	}
+
+	// 先解码所有数据，失败时回收已经创建的组件
+	entities := make([]loadedEntity, 0, len(snapshot.Entities))
+	release := func() {
+		for _, entity := range entities {
+			for _, component := range entity.components {
+				w.componentMap[ComponentId(component.ID())].DestroyComponent(component)
+			}
+		}
+	}
+	ids := make(map[EntityId]struct{}, len(snapshot.Entities))
+	for _, entitySnap := range snapshot.Entities {
+		ids[entitySnap.Id] = struct{}{}
+	}
+	for _, entitySnap := range snapshot.Entities {
+		loaded := loadedEntity{id: entitySnap.Id, components: make([]IComponent, 0, len(entitySnap.Components))}
+		for _, componentSnap := range entitySnap.Components {
+			componentType, ok := w.registry.Component(componentSnap.Type)
+			if !ok || componentType.newInfo != nil {
+				release()
+				return fmt.Errorf("ecs: component type %s is not registered", componentSnap.Type)
+			}
+			component := componentType.New()
+			loaded.components = append(loaded.components, component)
+			if err = decodeValue(format, componentSnap.Data, component); err != nil {
+				entities = append(entities, loaded)
+				release()
+				return fmt.Errorf("ecs: decode component %s: %w", componentSnap.Type, err)
+			}
+		}
+		entities = append(entities, loaded)
+		if err = w.loadSparse(&entities[len(entities)-1], format, entitySnap); err != nil {
+			release()
+			return err
+		}
+		if err = w.loadRelations(&entities[len(entities)-1], entitySnap, ids); err != nil {
+			release()
+			return err
+		}
+	}
+
+	resources := make([]IComponent, 0, len(snapshot.Resources))
+	for _, resourceSnap := range snapshot.Resources {
+		resourceType, ok := w.registry.Resource(resourceSnap.Type)
+		if !ok {
+			release()
+			return fmt.Errorf("ecs: resource type %s is not registered", resourceSnap.Type)
+		}
+		resource := resourceType.New()
+		if err = decodeValue(format, resourceSnap.Data, resource); err != nil {
+			release()
+			return fmt.Errorf("ecs: decode resource %s: %w", resourceSnap.Type, err)
+		}
+		resources = append(resources, resource)
+	}
+
+	// 销毁现有的实体，再以快照中的ID重新创建
+	existing := make([]IEntity, 0, len(w.entities))
+	for _, entity := range w.entities {
+		existing = append(existing, entity)
+	}
+	for _, entity := range existing {
+		w.destroy(entity)
+	}
+
+	restoredIds := make([]EntityId, 0, len(entities))
+	for _, loaded := range entities {
+		restoredIds = append(restoredIds, loaded.id)
+	}
+	w.allocator.restore(restoredIds)
+
+	restored := make([]IEntity, 0, len(entities))
+	for _, loaded := range entities {
+		entity := newEntityWithId(w, loaded.id)
+		w.registerEntity(entity)
+		restored = append(restored, entity)
+	}
+	for i, loaded := range entities {
+		for _, component := range loaded.components {
+			w.insertComponent(restored[i], ComponentId(component.ID()), component)
+		}
+		for _, sparse := range loaded.sparse {
+			componentInfo, _ := w.insertSparse(restored[i], sparse.componentType.Type, sparse.componentType.newInfo)
+			sparse.apply(componentInfo, loaded.id)
+		}
+	}
+	// 关系的目标可能排在后面，所有实体创建之后再添加
+	for _, loaded := range entities {
+		for _, relation := range loaded.relations {
+			w.relations.add(loaded.id, relation.relation, relation.target)
+		}
+	}
+
+	for _, resource := range resources {
+		w.commands.SetResource(resource)
+	}
+	return nil
+}
+
+// loadSparse 解码实体的标签和值组件，类型需要事先注册或使用过
+func (w *World) loadSparse(loaded *loadedEntity, format Format, entitySnap entitySnapshot) error {
+	for _, name := range entitySnap.Tags {
+		componentType, ok := w.registry.Component(name)
+		if !ok || componentType.newInfo == nil {
+			return fmt.Errorf("ecs: tag type %s is not registered", name)
+		}
+		if _, ok = componentType.newInfo().(*tagInfo); !ok {
+			return fmt.Errorf("ecs: type %s is not a tag", name)
+		}
+		loaded.sparse = append(loaded.sparse, loadedSparse{componentType: componentType, apply: func(IComponentInfo, EntityId) {}})
+	}
+	for _, valueSnap := range entitySnap.Values {
+		componentType, ok := w.registry.Component(valueSnap.Type)
+		if !ok || componentType.newInfo == nil {
+			return fmt.Errorf("ecs: value component type %s is not registered", valueSnap.Type)
+		}
+		apply, err := componentType.newInfo().(sparseCodec).decodeEntity(format, valueSnap.Data)
+		if err != nil {
+			return fmt.Errorf("ecs: decode value component %s: %w", valueSnap.Type, err)
+		}
+		loaded.sparse = append(loaded.sparse, loadedSparse{componentType: componentType, apply: apply})
+	}
+	return nil
+}
+
+// loadRelations 解码实体的关系对，目标必须是快照中的实体
+func (w *World) loadRelations(loaded *loadedEntity, entitySnap entitySnapshot, ids map[EntityId]struct{}) error {
+	for _, relationSnap := range entitySnap.Relations {
+		relationType, ok := w.registry.Relation(relationSnap.Type)
+		if !ok {
+			return fmt.Errorf("ecs: relation type %s is not registered", relationSnap.Type)
+		}
+		if _, ok = ids[relationSnap.Target]; !ok {
+			return fmt.Errorf("ecs: relation %s of entity %d targets missing entity %d", relationSnap.Type, entitySnap.Id, relationSnap.Target)
+		}
+		loaded.relations = append(loaded.relations, loadedRelation{relation: uint64(relationType.Id), target: relationSnap.Target})
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package ecs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testScore struct {
+	Component
+	Points int
+}
+
+func newTestSnapshotWorld(mode StorageMode) *World {
+	w := NewWorld(WithStorageMode(mode))
+	RegisterComponent[*testPosition](w)
+	RegisterComponent[*testVelocity](w)
+	RegisterResource[*testScore](w)
+	return w
+}
+
+func TestSnapshot_SaveLoad(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatBinary} {
+		for _, mode := range []StorageMode{StorageSparseSet, StorageArchetype} {
+			src := newTestSnapshotWorld(mode)
+			// 先销毁一个实体，加载后的实体ID需要保留代数
+			SpawnEmptyEntity(src)
+			src.destroy(SpawnEmptyEntity(src))
+
+			pos := SpawnComponent[*testPosition](src)
+			pos.X, pos.Y = 1, 2
+			parent := SpawnEmptyEntity(src, pos)
+			vel := SpawnComponent[*testVelocity](src)
+			vel.X = 3
+			child := SpawnEmptyEntity(src, vel)
+			src.GetCommands().AddChild(parent, child)
+			src.GetCommands().Execute()
+			score := &testScore{Points: 42}
+			src.GetCommands().SetResource(score)
+
+			var buf bytes.Buffer
+			if err := src.Save(&buf, format); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+
+			dst := newTestSnapshotWorld(mode)
+			SpawnEmptyEntity(dst, SpawnComponent[*testPosition](dst))
+			if err := dst.Load(&buf); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			if len(dst.GetEntities()) != 3 {
+				t.Fatalf("expected 3 entities, got %d", len(dst.GetEntities()))
+			}
+			loadedParent, ok := dst.GetEntity(EntityId(parent.ID()))
+			if !ok {
+				t.Fatalf("parent should keep its id %d", parent.ID())
+			}
+			if p := GetComponent[*testPosition](loadedParent); p == nil || p.X != 1 || p.Y != 2 {
+				t.Fatalf("position not restored: %+v", p)
+			}
+			children := GetChildren(loadedParent)
+			if len(children) != 1 || children[0].ID() != child.ID() || GetComponent[*testVelocity](children[0]).X != 3 {
+				t.Fatalf("hierarchy not restored")
+			}
+			if res, ok := GetResource[*testScore](NewResources(dst)); !ok || res.Points != 42 {
+				t.Fatalf("resource not restored")
+			}
+
+			// 加载后新分配的实体不会和快照中的实体冲突
+			fresh := SpawnEmptyEntity(dst)
+			if _, exists := dst.GetEntities()[EntityId(fresh.ID())]; !exists || fresh.ID() == parent.ID() || fresh.ID() == child.ID() {
+				t.Fatalf("fresh entity collides with loaded entities")
+			}
+		}
+	}
+}
+
+func TestSnapshot_Unregistered(t *testing.T) {
+	// 挂载到实体上的组件类型会被自动注册，保存时不需要显式注册
+	src := NewWorld()
+	SpawnEmptyEntity(src, SpawnComponent[*testFrozen](src))
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf, FormatJSON); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// 目标 World 没有注册组件类型时加载失败，现有实体保持不变
+	dst := NewWorld()
+	existing := SpawnEmptyEntity(dst)
+	if err := dst.Load(&buf); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("expected unregistered component error, got %v", err)
+	}
+	if !dst.IsAlive(existing) {
+		t.Fatalf("world should be unchanged after a failed load")
+	}
+}
+
+func TestSnapshot_TagsValuesRelations(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatBinary} {
+		for _, mode := range []StorageMode{StorageSparseSet, StorageArchetype} {
+			src := newTestSnapshotWorld(mode)
+			a := SpawnEmptyEntity(src, &testPosition{X: 1})
+			b := SpawnEmptyEntity(src)
+			c := SpawnEmptyEntity(src)
+			AddTag[testPlayer](a)
+			InsertValue(b, testSpeed{X: 2, Y: 3})
+			AddRelation[testLikes](src, a, c)
+			AddRelation[testLikes](src, a, b)
+			AddRelation[testOwnedBy](src, b, a)
+
+			var buf bytes.Buffer
+			if err := src.Save(&buf, format); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+
+			// 目标 World 需要注册快照中出现的标签、值组件和关系
+			dst := newTestSnapshotWorld(mode)
+			RegisterTag[testPlayer](dst)
+			RegisterValue[testSpeed](dst)
+			RegisterRelation[testLikes](dst)
+			RegisterRelation[testOwnedBy](dst)
+			if err := dst.Load(&buf); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			loadedA, _ := dst.GetEntity(EntityId(a.ID()))
+			loadedB, _ := dst.GetEntity(EntityId(b.ID()))
+			loadedC, _ := dst.GetEntity(EntityId(c.ID()))
+			if !HasTag[testPlayer](loadedA) || HasTag[testPlayer](loadedB) {
+				t.Fatalf("tags not restored")
+			}
+			if v := GetValue[testSpeed](loadedB); v == nil || v.X != 2 || v.Y != 3 {
+				t.Fatalf("value component not restored: %+v", v)
+			}
+			if targets := Targets[testLikes](dst, loadedA); len(targets) != 2 || targets[0].ID() != c.ID() || targets[1].ID() != b.ID() {
+				t.Fatalf("relation targets not restored in order")
+			}
+			if !HasRelation[testOwnedBy](dst, loadedB, loadedA) || HasRelation[testLikes](dst, loadedC, nil) {
+				t.Fatalf("relations not restored")
+			}
+		}
+	}
+}
+
+func TestSnapshot_UnregisteredTag(t *testing.T) {
+	src := NewWorld()
+	AddTag[testDead](SpawnEmptyEntity(src))
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf, FormatJSON); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	dst := NewWorld()
+	existing := SpawnEmptyEntity(dst)
+	if err := dst.Load(&buf); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("expected unregistered tag error, got %v", err)
+	}
+	if !dst.IsAlive(existing) {
+		t.Fatalf("world should be unchanged after a failed load")
+	}
+}
@@ -7,20 +7,42 @@ type ISystem interface {
 	RangeEntities(fn func(entity IEntity))
 }
 
+// IEntitySystem 逐实体更新的系统：调度器会遍历 queryList 匹配的实体，逐个调用 UpdateEntity
+type IEntitySystem interface {
+	ISystem
+	UpdateEntity(entity IEntity)
+}
+
+// IBeforeUpdateEntities 每帧逐实体更新之前调用
+type IBeforeUpdateEntities interface {
+	BeforeUpdateEntities()
+}
+
+// IAfterUpdateEntities 每帧逐实体更新之后调用
+type IAfterUpdateEntities interface {
+	AfterUpdateEntities()
+}
+
 type System struct {
 	ISystem
 	World     *World
 	Commands  *Commands
 	Query     *Query
 	queryList []IComponent
+	ticks     *runTicks
+	access    *Access
 }
 
 func NewSystem(w *World, queryList ...IComponent) *System {
+	ticks := newRunTicks()
+	commands := NewCommands(w)
 	return &System{
 		World:     w,
-		Commands:  w.commands,
-		Query:     w.query,
+		Commands:  commands,
+		Query:     newQueryWithTicks(w, ticks, commands),
 		queryList: queryList,
+		ticks:     ticks,
+		access:    NewAccess(),
 	}
 }
 
@@ -28,6 +50,30 @@ func (s *System) GetWorld() *World {
 	return s.World
 }
 
+func (s *System) getTicks() *runTicks {
+	return s.ticks
+}
+
+func (s *System) getCommands() *Commands {
+	return s.Commands
+}
+
+// systemTicks 获取系统的运行 tick，未嵌入 System 的系统不参与变更检测
+func systemTicks(system ISystem) *runTicks {
+	if ctx, ok := system.(QueryContext); ok {
+		return ctx.getTicks()
+	}
+	return nil
+}
+
+// systemCommands 获取系统的命令缓冲，未嵌入 System 的系统没有独立的缓冲
+func systemCommands(system ISystem) *Commands {
+	if ctx, ok := system.(QueryContext); ok {
+		return ctx.getCommands()
+	}
+	return nil
+}
+
 func (s *System) StartUp() {
 }
 
@@ -39,3 +85,21 @@ func (s *System) RangeEntities(fn func(entity IEntity)) {
 		fn(entity)
 	}
 }
+
+// runUpdate 执行系统的 Update，实现了 IEntitySystem 的系统再对匹配的实体逐个更新
+func runUpdate(system ISystem) {
+	system.Update()
+
+	entitySystem, ok := system.(IEntitySystem)
+	if !ok {
+		return
+	}
+
+	if before, ok := system.(IBeforeUpdateEntities); ok {
+		before.BeforeUpdateEntities()
+	}
+	entitySystem.RangeEntities(entitySystem.UpdateEntity)
+	if after, ok := system.(IAfterUpdateEntities); ok {
+		after.AfterUpdateEntities()
+	}
+}
@@ -0,0 +1,54 @@
+package ecs
+
+import (
+	"testing"
+)
+
+type testEntitySystem struct {
+	System
+	calls []string
+}
+
+func newTestEntitySystem(w *World) *testEntitySystem {
+	return &testEntitySystem{
+		System: *NewSystem(w, &testPosition{}),
+	}
+}
+
+func (s *testEntitySystem) BeforeUpdateEntities() {
+	s.calls = append(s.calls, "before")
+}
+
+func (s *testEntitySystem) UpdateEntity(entity IEntity) {
+	GetComponent[*testPosition](entity).X++
+	s.calls = append(s.calls, "entity")
+}
+
+func (s *testEntitySystem) AfterUpdateEntities() {
+	s.calls = append(s.calls, "after")
+}
+
+func TestSystem_UpdateEntity(t *testing.T) {
+	w := NewWorld()
+	s := newTestEntitySystem(w)
+	w.AddUpdateSystem(s)
+
+	a := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+	SpawnEmptyEntity(w, SpawnComponent[*testVelocity](w))
+
+	w.Update()
+
+	expected := []string{"before", "entity", "entity", "after"}
+	if len(s.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, s.calls)
+	}
+	for i := range expected {
+		if s.calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, s.calls)
+		}
+	}
+	if GetComponent[*testPosition](a).X != 1 {
+		t.Fatalf("UpdateEntity did not run for entity %d", a.ID())
+	}
+}
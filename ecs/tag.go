@@ -0,0 +1,97 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/INT-Game/go-ecs/sparse_set"
+)
+
+// tagInfo 标签只记录在稀疏集中，没有组件实例，也不占用实体的组件容器和原型的列
+type tagInfo struct {
+	ComponentInfo[IComponent]
+}
+
+func newTagInfo() *tagInfo {
+	return &tagInfo{
+		ComponentInfo: ComponentInfo[IComponent]{
+			sparseSet: sparse_set.NewSparseSet[uint64](32),
+			ticks:     make([]ComponentTicks, 0),
+		},
+	}
+}
+
+func (t *tagInfo) CreateComponent() IComponent {
+	return nil
+}
+
+func (t *tagInfo) DestroyComponent(elem IComponent) {
+
+}
+
+func (t *tagInfo) encodeEntity(format Format, entityId EntityId) ([]byte, error) {
+	return nil, nil
+}
+
+func (t *tagInfo) decodeEntity(format Format, data []byte) (func(componentInfo IComponentInfo, entityId EntityId), error) {
+	return func(componentInfo IComponentInfo, entityId EntityId) {}, nil
+}
+
+func newTagInfoFunc() IComponentInfo {
+	return newTagInfo()
+}
+
+// AddTag 为实体添加标签，标签类型通常是空结构体
+//
+//	type Player struct{}
+//	ecs.AddTag[Player](entity)
+func AddTag[T any](entity IEntity) {
+	componentInfo, ok := internalsOf(entity.GetEcsWorld()).insertSparse(entity, typeOf[T](), newTagInfoFunc)
+	if !ok {
+		return
+	}
+	if _, ok = componentInfo.(*tagInfo); !ok {
+		panic(fmt.Errorf("ecs: %s is used as both a value component and a tag", typeOf[T]()))
+	}
+}
+
+// RemoveTag 移除实体的标签
+func RemoveTag[T any](entity IEntity) {
+	internalsOf(entity.GetEcsWorld()).removeSparse(entity, typeOf[T]())
+}
+
+// HasTag 判断实体是否有标签
+func HasTag[T any](entity IEntity) bool {
+	componentInfo, ok := internalsOf(entity.GetEcsWorld()).sparseInfoOf(typeOf[T]())
+	if !ok {
+		return false
+	}
+	_, ok = componentInfo.(*tagInfo)
+	return ok && entity.GetEcsWorld().IsAlive(entity) && componentInfo.Contains(EntityId(entity.ID()))
+}
+
+// WithTag 实体必须有指定标签
+func WithTag[T any]() Term {
+	return Term{kind: termTag, types: []reflect.Type{typeOf[T]()}}
+}
+
+// WithoutTag 实体不能有指定标签
+func WithoutTag[T any]() Term {
+	return Term{kind: termNotTag, types: []reflect.Type{typeOf[T]()}}
+}
+
+// matchTags 检查标签和值组件条件，它们不在原型中，原型存储模式下也需要逐个实体检查
+func (f *Filter) matchTags(w *World, entityId EntityId) bool {
+	for _, componentId := range f.tags {
+		componentInfo, ok := w.componentMap[componentId]
+		if !ok || !componentInfo.Contains(entityId) {
+			return false
+		}
+	}
+	for _, componentId := range f.notTags {
+		if componentInfo, ok := w.componentMap[componentId]; ok && componentInfo.Contains(entityId) {
+			return false
+		}
+	}
+	return true
+}
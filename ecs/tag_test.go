@@ -0,0 +1,71 @@
+package ecs
+
+import "testing"
+
+type testPlayer struct{}
+
+type testDead struct{}
+
+func TestTag_AddRemove(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			a := SpawnEmptyEntity(w, &testPosition{X: 1})
+			b := SpawnEmptyEntity(w, &testPosition{X: 2})
+			c := SpawnEmptyEntity(w)
+
+			AddTag[testPlayer](a)
+			AddTag[testPlayer](c)
+			AddTag[testDead](b)
+			if !HasTag[testPlayer](a) || HasTag[testPlayer](b) || !HasTag[testDead](b) {
+				t.Fatalf("unexpected tags")
+			}
+			if len(a.GetComponentContainer()) > 1 {
+				t.Fatalf("tags should not be stored in the component container")
+			}
+
+			players := NewQuery1[*testPosition](w, WithTag[testPlayer]()).Entities()
+			if len(players) != 1 || players[0] != a {
+				t.Fatalf("expected only a, got %v", entityIds(players))
+			}
+			alive := NewQuery1[*testPosition](w, WithoutTag[testDead]()).Entities()
+			if len(alive) != 1 || alive[0] != a {
+				t.Fatalf("expected only a, got %v", entityIds(alive))
+			}
+			if n := len(w.GetQuery().Filter(WithTag[testPlayer]())); n != 2 {
+				t.Fatalf("tag alone should drive the query, got %d", n)
+			}
+
+			RemoveTag[testPlayer](a)
+			if HasTag[testPlayer](a) || NewQuery1[*testPosition](w, WithTag[testPlayer]()).Count() != 0 {
+				t.Fatalf("tag should be removed")
+			}
+		})
+	}
+}
+
+func TestTag_Destroy(t *testing.T) {
+	w := NewWorld()
+	a := SpawnEmptyEntity(w)
+	AddTag[testPlayer](a)
+	w.GetCommands().DestroyEntity(a).Execute()
+
+	// 槽位复用后新实体不应该继承标签
+	b := SpawnEmptyEntity(w)
+	if EntityId(a.ID()).Index() != EntityId(b.ID()).Index() {
+		t.Fatalf("expected the slot to be reused")
+	}
+	if HasTag[testPlayer](b) || HasTag[testPlayer](a) {
+		t.Fatalf("tags should be cleared on destroy")
+	}
+}
+
+func TestTag_ComponentConflict(t *testing.T) {
+	w := NewWorld()
+	entity := SpawnEmptyEntity(w, &testPosition{})
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("using a component type as a tag should panic")
+		}
+	}()
+	AddTag[*testPosition](entity)
+}
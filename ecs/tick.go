@@ -0,0 +1,166 @@
+package ecs
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// ComponentTicks 组件被添加和最后一次被修改时的 tick
+type ComponentTicks struct {
+	Added   uint64
+	Changed uint64
+}
+
+// runTicks 系统（或 World）上一次运行和本次运行时的 tick
+type runTicks struct {
+	lastRun uint64
+	thisRun uint64
+}
+
+func newRunTicks() *runTicks {
+	return &runTicks{}
+}
+
+// isNewer 判断 tick 是否发生在上一次运行之后
+func (t *runTicks) isNewer(tick uint64) bool {
+	return tick > t.lastRun
+}
+
+// QueryContext 查询的上下文，决定 Added / Changed 过滤条件以哪一次运行为基准
+// World 以上一次 Update 结束为基准，System 以该系统上一次运行为基准
+type QueryContext interface {
+	GetWorld() *World
+	getTicks() *runTicks
+	getCommands() *Commands
+}
+
+// Added 组件在上一次运行之后被添加
+func Added[T IComponent]() Term {
+	return Term{kind: termAdded, types: []reflect.Type{typeOf[T]()}}
+}
+
+// Changed 组件在上一次运行之后被添加或修改
+func Changed[T IComponent]() Term {
+	return Term{kind: termChanged, types: []reflect.Type{typeOf[T]()}}
+}
+
+// matchTicks 检查 Added / Changed 条件
+func (f *Filter) matchTicks(w *World, entityId EntityId, ticks *runTicks) bool {
+	for _, componentId := range f.added {
+		componentTicks, ok := w.componentTicks(entityId, componentId)
+		if !ok || !ticks.isNewer(componentTicks.Added) {
+			return false
+		}
+	}
+	for _, componentId := range f.changed {
+		componentTicks, ok := w.componentTicks(entityId, componentId)
+		if !ok || !ticks.isNewer(componentTicks.Changed) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *World) componentTicks(entityId EntityId, componentId ComponentId) (ComponentTicks, bool) {
+	componentInfo, ok := w.componentMap[componentId]
+	if !ok {
+		return ComponentTicks{}, false
+	}
+	return componentInfo.GetTicks(entityId)
+}
+
+// ChangeTick 当前的变更 tick
+func (w *World) ChangeTick() uint64 {
+	return atomic.LoadUint64(&w.changeTick)
+}
+
+// runWithTicks 为本次运行领取一个新的 tick，运行结束后记录为上一次运行的 tick
+func (w *World) runWithTicks(ticks *runTicks, run func()) {
+	tick := atomic.AddUint64(&w.changeTick, 1)
+	if ticks != nil {
+		ticks.thisRun = tick
+	}
+	w.activeMu.Lock()
+	w.activeTicks = append(w.activeTicks, tick)
+	w.activeMu.Unlock()
+
+	run()
+
+	w.activeMu.Lock()
+	for i, active := range w.activeTicks {
+		if active == tick {
+			w.activeTicks = append(w.activeTicks[:i], w.activeTicks[i+1:]...)
+			break
+		}
+	}
+	w.activeMu.Unlock()
+	if ticks != nil {
+		ticks.lastRun = tick
+	}
+}
+
+// systemRunning 是否有系统正在运行
+func (w *World) systemRunning() bool {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	return len(w.activeTicks) > 0
+}
+
+// writeTick 添加或修改组件时记录的 tick
+// ticks 属于正在运行的系统时使用该系统本次运行领取的 tick；
+// 否则使用正在运行的系统中最早领取的 tick，没有系统在运行时使用当前的变更 tick。
+// 同时运行的系统访问不冲突，看不到彼此写入的组件，使用最早的 tick 保证每个系统都不会把自己的写入当成新的修改
+func (w *World) writeTick(ticks *runTicks) uint64 {
+	if ticks != nil && ticks != w.ticks && ticks.thisRun > ticks.lastRun {
+		return ticks.thisRun
+	}
+
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	if len(w.activeTicks) == 0 {
+		return w.ChangeTick()
+	}
+	tick := w.activeTicks[0]
+	for _, active := range w.activeTicks[1:] {
+		tick = min(tick, active)
+	}
+	return tick
+}
+
+func (w *World) markChanged(e IEntity, componentId ComponentId) {
+	w.markChangedIn(e, componentId, nil)
+}
+
+// markChangedIn 以 ticks 所属的系统或 World 的名义标记组件已被修改
+func (w *World) markChangedIn(e IEntity, componentId ComponentId, ticks *runTicks) {
+	if componentInfo, ok := w.componentMap[componentId]; ok {
+		componentInfo.SetChangedTick(EntityId(e.ID()), w.writeTick(ticks))
+	}
+}
+
+// MarkChanged 标记实体的组件已被修改
+func (w *World) MarkChanged(e IEntity, components ...IComponent) {
+	for _, component := range components {
+		w.markChanged(e, ComponentId(w.GetCompId(reflect.TypeOf(component))))
+	}
+}
+
+// MarkChanged 标记实体的组件已被修改，修改记录为系统本次运行的 tick
+func (s *System) MarkChanged(e IEntity, components ...IComponent) {
+	for _, component := range components {
+		s.World.markChangedIn(e, ComponentId(s.World.GetCompId(reflect.TypeOf(component))), s.ticks)
+	}
+}
+
+// GetComponentMut 以可变方式获取实体组件，组件会被标记为已修改
+func GetComponentMut[T IComponent](e IEntity) T {
+	w := internalsOf(e.GetEcsWorld())
+	componentId := ComponentId(w.GetCompId(typeOf[T]()))
+	component, ok := w.getComponent(e, componentId)
+	if !ok {
+		var zero T
+		return zero
+	}
+	w.markChanged(e, componentId)
+	return component.(T)
+}
@@ -0,0 +1,124 @@
+package ecs
+
+import (
+	"sync"
+	"testing"
+)
+
+type testChangeSystem struct {
+	System
+	added   *Query1[*testPosition]
+	changed *Query1[*testPosition]
+
+	seenAdded   int
+	seenChanged int
+}
+
+func newTestChangeSystem(w *World) *testChangeSystem {
+	s := &testChangeSystem{System: *NewSystem(w)}
+	s.added = NewQuery1[*testPosition](s, Added[*testPosition]())
+	s.changed = NewQuery1[*testPosition](s, Changed[*testPosition]())
+	return s
+}
+
+func (s *testChangeSystem) Update() {
+	s.seenAdded = s.added.Count()
+	s.seenChanged = s.changed.Count()
+}
+
+func TestChangeDetection_Systems(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			s := newTestChangeSystem(w)
+			w.AddUpdateSystem(s)
+
+			a := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+			SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+
+			w.Update()
+			if s.seenAdded != 2 || s.seenChanged != 2 {
+				t.Fatalf("first run: expected 2 added / 2 changed, got %d / %d", s.seenAdded, s.seenChanged)
+			}
+
+			w.Update()
+			if s.seenAdded != 0 || s.seenChanged != 0 {
+				t.Fatalf("second run: expected nothing, got %d / %d", s.seenAdded, s.seenChanged)
+			}
+
+			GetComponentMut[*testPosition](a).X = 10
+			w.Update()
+			if s.seenAdded != 0 || s.seenChanged != 1 {
+				t.Fatalf("after mutation: expected 0 added / 1 changed, got %d / %d", s.seenAdded, s.seenChanged)
+			}
+		})
+	}
+}
+
+func TestChangeDetection_WorldContext(t *testing.T) {
+	w := NewWorld()
+	e := SpawnEmptyEntity(w, SpawnComponent[*testPosition](w))
+
+	changed := NewQuery1[*testPosition](w, Changed[*testPosition]())
+	if n := changed.Count(); n != 1 {
+		t.Fatalf("expected 1 changed before update, got %d", n)
+	}
+
+	w.Update()
+	if n := changed.Count(); n != 0 {
+		t.Fatalf("expected 0 changed after update, got %d", n)
+	}
+
+	w.MarkChanged(e, &testPosition{})
+	if n := changed.Count(); n != 1 {
+		t.Fatalf("expected 1 changed after MarkChanged, got %d", n)
+	}
+}
+
+// testWriterSystem 每帧先统计自己写入的组件中被修改的数量，再修改它
+type testWriterSystem struct {
+	System
+	arrive  *sync.WaitGroup
+	changed interface{ Count() int }
+	write   func(entity IEntity)
+	seen    []int
+}
+
+func (s *testWriterSystem) Update() {
+	// 等待两个系统都领取了 tick，保证它们同时运行
+	s.arrive.Done()
+	s.arrive.Wait()
+	s.seen = append(s.seen, s.changed.Count())
+	for _, entity := range s.World.GetQuery().Query(&testPosition{}) {
+		s.write(entity)
+	}
+}
+
+func TestChangeDetection_ParallelSystems(t *testing.T) {
+	w := NewWorld(WithParallelism(2))
+	SpawnEmptyEntity(w, &testPosition{}, &testVelocity{})
+
+	arrive := &sync.WaitGroup{}
+	a := &testWriterSystem{System: *NewSystem(w), arrive: arrive}
+	a.Writes(&testPosition{})
+	a.changed = NewQuery1[*testPosition](a, Changed[*testPosition]())
+	a.write = func(entity IEntity) { GetComponentMut[*testPosition](entity) }
+
+	b := &testWriterSystem{System: *NewSystem(w), arrive: arrive}
+	b.Writes(&testVelocity{})
+	b.changed = NewQuery1[*testVelocity](b, Changed[*testVelocity]())
+	b.write = func(entity IEntity) { b.MarkChanged(entity, &testVelocity{}) }
+
+	w.AddUpdateSystem(a)
+	w.AddUpdateSystem(b)
+	for i := 0; i < 4; i++ {
+		arrive.Add(2)
+		w.Update()
+	}
+
+	// 系统不会把自己上一帧的写入当成新的修改
+	for _, s := range []*testWriterSystem{a, b} {
+		if len(s.seen) != 4 || s.seen[0] != 1 || s.seen[1] != 0 || s.seen[2] != 0 || s.seen[3] != 0 {
+			t.Fatalf("expected [1 0 0 0], got %v", s.seen)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/INT-Game/go-ecs/sparse_set"
+)
+
+// deferredStorage 系统运行期间延迟结构变化的存储，同步点调用 flush
+type deferredStorage interface {
+	addDeferred(e IEntity)
+	removeDeferred(e IEntity)
+	flush()
+}
+
+// ValueInfo 值组件的存储，组件以值的形式保存在与稀疏集 density 一一对应的连续数组中
+// 值组件是普通结构体，不需要嵌入 Component，也不经过组件池和实体的组件容器
+// 系统运行期间数组不会移动：新增的值单独分配，移除只做标记，到同步点再合并到数组中，
+// 因此一次系统运行中取得的指针始终指向同一个实体的值
+type ValueInfo[T any] struct {
+	ComponentInfo[IComponent]
+	values   []T
+	overflow []*T               // 系统运行期间新增的值，对应 density 中 values 之后的部分
+	removed  map[uint64]IEntity // 系统运行期间移除的实体，到同步点才从稀疏集中删除
+}
+
+func NewValueInfo[T any]() *ValueInfo[T] {
+	return &ValueInfo[T]{
+		ComponentInfo: ComponentInfo[IComponent]{
+			sparseSet: sparse_set.NewSparseSet[uint64](32),
+			ticks:     make([]ComponentTicks, 0),
+		},
+		values:   make([]T, 0),
+		overflow: make([]*T, 0),
+		removed:  make(map[uint64]IEntity),
+	}
+}
+
+func (c *ValueInfo[T]) AddEntity(e IEntity) {
+	c.flush()
+	if c.sparseSet.Contains(entityIndex(e)) {
+		return
+	}
+	c.ComponentInfo.AddEntity(e)
+	var zero T
+	c.values = append(c.values, zero)
+}
+
+func (c *ValueInfo[T]) RemoveEntity(e IEntity) {
+	c.flush()
+	i, ok := c.sparseSet.Index(entityIndex(e))
+	if !ok {
+		return
+	}
+
+	// 与稀疏集和 tick 保持同样的交换
+	var zero T
+	last := len(c.values) - 1
+	c.values[i] = c.values[last]
+	c.values[last] = zero
+	c.values = c.values[:last]
+	c.ComponentInfo.RemoveEntity(e)
+}
+
+// addDeferred 系统运行期间添加，值单独分配，数组中已有的值不会移动
+func (c *ValueInfo[T]) addDeferred(e IEntity) {
+	index := entityIndex(e)
+	if _, ok := c.removed[index]; ok {
+		// 同一次运行中移除后又添加，复用原来的位置并清零
+		delete(c.removed, index)
+		var zero T
+		*c.Get(EntityId(e.ID())) = zero
+		return
+	}
+	if c.sparseSet.Contains(index) {
+		return
+	}
+	c.ComponentInfo.AddEntity(e)
+	c.overflow = append(c.overflow, new(T))
+}
+
+// removeDeferred 系统运行期间移除，只做标记，值留在原来的位置
+func (c *ValueInfo[T]) removeDeferred(e IEntity) {
+	if c.Contains(EntityId(e.ID())) {
+		c.removed[entityIndex(e)] = e
+	}
+}
+
+// flush 在同步点把单独分配的值合并到数组中，再删除标记为移除的实体
+func (c *ValueInfo[T]) flush() {
+	if len(c.overflow) == 0 && len(c.removed) == 0 {
+		return
+	}
+	for i, value := range c.overflow {
+		c.values = append(c.values, *value)
+		c.overflow[i] = nil
+	}
+	c.overflow = c.overflow[:0]
+
+	removed := make([]uint64, 0, len(c.removed))
+	for index := range c.removed {
+		removed = append(removed, index)
+	}
+	slices.Sort(removed)
+	entities := c.removed
+	c.removed = make(map[uint64]IEntity)
+	for _, index := range removed {
+		c.RemoveEntity(entities[index])
+	}
+}
+
+func (c *ValueInfo[T]) CreateComponent() IComponent {
+	return nil
+}
+
+func (c *ValueInfo[T]) DestroyComponent(elem IComponent) {
+
+}
+
+// Contains 系统运行期间移除的实体已经不再拥有该值组件
+func (c *ValueInfo[T]) Contains(entityId EntityId) bool {
+	if _, ok := c.removed[uint64(entityId.Index())]; ok {
+		return false
+	}
+	return c.ComponentInfo.Contains(entityId)
+}
+
+// Get 实体的值组件，实体没有该组件时返回 nil
+func (c *ValueInfo[T]) Get(entityId EntityId) *T {
+	if _, ok := c.removed[uint64(entityId.Index())]; ok {
+		return nil
+	}
+	i, ok := c.sparseSet.Index(uint64(entityId.Index()))
+	if !ok {
+		return nil
+	}
+	return c.At(i)
+}
+
+// At 与 Density 中第 i 个实体对应的值
+func (c *ValueInfo[T]) At(i int) *T {
+	if i < len(c.values) {
+		return &c.values[i]
+	}
+	return c.overflow[i-len(c.values)]
+}
+
+func (c *ValueInfo[T]) encodeEntity(format Format, entityId EntityId) ([]byte, error) {
+	value := c.Get(entityId)
+	if value == nil {
+		return nil, fmt.Errorf("ecs: entity %d has no value component %s", entityId, typeOf[T]())
+	}
+	return encodeValue(format, value)
+}
+
+func (c *ValueInfo[T]) decodeEntity(format Format, data []byte) (func(componentInfo IComponentInfo, entityId EntityId), error) {
+	var value T
+	if err := decodeValue(format, data, &value); err != nil {
+		return nil, err
+	}
+	return func(componentInfo IComponentInfo, entityId EntityId) {
+		*componentInfo.(*ValueInfo[T]).Get(entityId) = value
+	}, nil
+}
+
+func newValueInfoFunc[T any]() func() IComponentInfo {
+	return func() IComponentInfo {
+		return NewValueInfo[T]()
+	}
+}
+
+// sparseInfoOf 查找只记录在稀疏集中的 ComponentInfo
+func (w *World) sparseInfoOf(t reflect.Type) (IComponentInfo, bool) {
+	componentId := ComponentId(w.GetCompId(t))
+	if _, ok := w.sparseOnly[componentId]; !ok {
+		return nil, false
+	}
+	return w.componentMap[componentId], true
+}
+
+// insertSparse 将实体加入稀疏集，类型第一次出现时用 create 创建 ComponentInfo，与组件共用组件ID
+// 新加入时设置 Added，已经存在时设置 Changed；实体已销毁时返回 false
+func (w *World) insertSparse(e IEntity, t reflect.Type, create func() IComponentInfo) (IComponentInfo, bool) {
+	if !w.IsAlive(e) {
+		return nil, false
+	}
+
+	componentId := ComponentId(w.GetCompId(t))
+	componentInfo, ok := w.componentMap[componentId]
+	if !ok {
+		componentInfo = create()
+		w.componentMap[componentId] = componentInfo
+		w.sparseOnly[componentId] = struct{}{}
+		if _, ok = w.registry.ComponentOf(t); !ok {
+			w.registerAuto(w.registry.components, w.compIdGetter, newSparseType(t, create))
+		}
+	} else if _, ok = w.sparseOnly[componentId]; !ok {
+		panic(fmt.Errorf("ecs: %s is used as both a component and a tag or value component", t))
+	}
+
+	entityId := EntityId(e.ID())
+	if componentInfo.Contains(entityId) {
+		componentInfo.SetChangedTick(entityId, w.writeTick(nil))
+	} else {
+		if storage, ok := componentInfo.(deferredStorage); ok && w.systemRunning() {
+			storage.addDeferred(e)
+		} else {
+			componentInfo.AddEntity(e)
+		}
+		componentInfo.SetAddedTick(entityId, w.writeTick(nil))
+	}
+	return componentInfo, true
+}
+
+func (w *World) removeSparse(e IEntity, t reflect.Type) {
+	if componentInfo, ok := w.sparseInfoOf(t); ok {
+		w.removeSparseFrom(componentInfo, e)
+	}
+}
+
+func (w *World) removeSparseFrom(componentInfo IComponentInfo, e IEntity) {
+	if storage, ok := componentInfo.(deferredStorage); ok && w.systemRunning() {
+		storage.removeDeferred(e)
+		return
+	}
+	componentInfo.RemoveEntity(e)
+}
+
+// flushValues 同步点把系统运行期间延迟的增删合并到值组件的数组中
+func (w *World) flushValues() {
+	for componentId := range w.sparseOnly {
+		if storage, ok := w.componentMap[componentId].(deferredStorage); ok {
+			storage.flush()
+		}
+	}
+}
+
+// removeSparseOnly 实体销毁时清除它的标签和值组件，避免槽位复用后新实体继承
+func (w *World) removeSparseOnly(entity IEntity) {
+	for componentId := range w.sparseOnly {
+		w.removeSparseFrom(w.componentMap[componentId], entity)
+	}
+}
+
+func valueInfoOf[T any](w IWorld) (*ValueInfo[T], bool) {
+	componentInfo, ok := internalsOf(w).sparseInfoOf(typeOf[T]())
+	if !ok {
+		return nil, false
+	}
+	valueInfo, ok := componentInfo.(*ValueInfo[T])
+	return valueInfo, ok
+}
+
+// InsertValue 为实体添加值组件，已存在时覆盖原来的值
+//
+//	type Velocity struct{ X, Y float64 }
+//	ecs.InsertValue(entity, Velocity{X: 1})
+func InsertValue[T any](entity IEntity, value T) {
+	componentInfo, ok := internalsOf(entity.GetEcsWorld()).insertSparse(entity, typeOf[T](), newValueInfoFunc[T]())
+	if !ok {
+		return
+	}
+	valueInfo, ok := componentInfo.(*ValueInfo[T])
+	if !ok {
+		panic(fmt.Errorf("ecs: %s is used as both a tag and a value component", typeOf[T]()))
+	}
+	*valueInfo.Get(EntityId(entity.ID())) = value
+}
+
+// RemoveValue 移除实体的值组件
+func RemoveValue[T any](entity IEntity) {
+	internalsOf(entity.GetEcsWorld()).removeSparse(entity, typeOf[T]())
+}
+
+// GetValue 实体值组件的指针，不存在时返回 nil
+// 系统运行期间同类型值组件的增删延迟到同步点，指针在本次系统运行中保持有效
+func GetValue[T any](entity IEntity) *T {
+	valueInfo, ok := valueInfoOf[T](entity.GetEcsWorld())
+	if !ok || !entity.GetEcsWorld().IsAlive(entity) {
+		return nil
+	}
+	return valueInfo.Get(EntityId(entity.ID()))
+}
+
+// GetValueMut 与 GetValue 相同，同时将值组件标记为已修改
+func GetValueMut[T any](entity IEntity) *T {
+	value := GetValue[T](entity)
+	if value != nil {
+		w := internalsOf(entity.GetEcsWorld())
+		w.markChanged(entity, ComponentId(w.GetCompId(typeOf[T]())))
+	}
+	return value
+}
+
+// WithValue 实体必须有指定值组件
+func WithValue[T any]() Term {
+	return Term{kind: termTag, types: []reflect.Type{typeOf[T]()}}
+}
+
+// WithoutValue 实体不能有指定值组件
+func WithoutValue[T any]() Term {
+	return Term{kind: termNotTag, types: []reflect.Type{typeOf[T]()}}
+}
+
+// AddedValue 值组件在上一次运行之后被添加
+func AddedValue[T any]() Term {
+	return Term{kind: termAddedValue, types: []reflect.Type{typeOf[T]()}}
+}
+
+// ChangedValue 值组件在上一次运行之后被添加或通过 GetValueMut、InsertValue 修改
+func ChangedValue[T any]() Term {
+	return Term{kind: termChangedValue, types: []reflect.Type{typeOf[T]()}}
+}
+
+// ValueQuery 按连续数组的顺序遍历值组件，terms 中的组件、标签和关系条件逐个实体检查
+type ValueQuery[T any] struct {
+	w      *World
+	ticks  *runTicks
+	filter *Filter
+}
+
+func NewValueQuery[T any](ctx QueryContext, terms ...Term) *ValueQuery[T] {
+	w := ctx.GetWorld()
+	return &ValueQuery[T]{
+		w:      w,
+		ticks:  ctx.getTicks(),
+		filter: NewFilter(w, terms...),
+	}
+}
+
+// ForEach 按 density 的顺序遍历所有匹配的实体
+// 系统中遍历时增删同类型的值组件会延迟到同步点，不影响本次遍历；系统之外遍历时请通过 Commands 延迟执行
+func (q *ValueQuery[T]) ForEach(fn func(entity IEntity, value *T)) {
+	valueInfo, ok := valueInfoOf[T](q.w)
+	if !ok {
+		return
+	}
+	density := valueInfo.Density()
+	for i := 0; i < len(density); i++ {
+		entity, exists := q.w.entityAt(uint32(density[i]))
+		if !exists {
+			continue
+		}
+		entityId := EntityId(entity.ID())
+		if !valueInfo.Contains(entityId) || !q.filter.matchEntity(q.w, entityId) || !q.filter.matchRow(q.w, entityId, q.ticks) {
+			continue
+		}
+		fn(entity, valueInfo.At(i))
+	}
+}
+
+// Count 匹配的实体数量
+func (q *ValueQuery[T]) Count() int {
+	count := 0
+	q.ForEach(func(entity IEntity, value *T) {
+		count++
+	})
+	return count
+}
@@ -0,0 +1,161 @@
+package ecs
+
+import "testing"
+
+type testSpeed struct {
+	X, Y float64
+}
+
+func TestValue_InsertRemove(t *testing.T) {
+	for name, w := range newTestWorlds() {
+		t.Run(name, func(t *testing.T) {
+			a := SpawnEmptyEntity(w, &testPosition{})
+			b := SpawnEmptyEntity(w)
+			c := SpawnEmptyEntity(w, &testPosition{})
+
+			InsertValue(a, testSpeed{X: 1})
+			InsertValue(b, testSpeed{X: 2})
+			InsertValue(c, testSpeed{X: 3})
+			if v := GetValue[testSpeed](b); v == nil || v.X != 2 {
+				t.Fatalf("value should be stored, got %v", v)
+			}
+			if len(a.GetComponentContainer()) > 1 {
+				t.Fatalf("values should not be stored in the component container")
+			}
+
+			// 删除后最后一个元素被交换到空出的位置，值与实体的对应关系保持不变
+			RemoveValue[testSpeed](a)
+			if GetValue[testSpeed](a) != nil || GetValue[testSpeed](c).X != 3 || GetValue[testSpeed](b).X != 2 {
+				t.Fatalf("values should follow their entities after removal")
+			}
+
+			sum := 0.0
+			q := NewValueQuery[testSpeed](w, With(&testPosition{}))
+			q.ForEach(func(entity IEntity, value *testSpeed) {
+				value.Y = 10
+				sum += value.X
+			})
+			if sum != 3 || q.Count() != 1 {
+				t.Fatalf("expected only c to match, got sum %v", sum)
+			}
+			if GetValue[testSpeed](c).Y != 10 {
+				t.Fatalf("values should be modified in place")
+			}
+			if n := len(w.GetQuery().Filter(WithValue[testSpeed]())); n != 2 {
+				t.Fatalf("value components should be usable in filters, got %d", n)
+			}
+
+			w.GetCommands().DestroyEntity(c).Execute()
+			d := SpawnEmptyEntity(w)
+			if GetValue[testSpeed](d) != nil {
+				t.Fatalf("values should be cleared on destroy")
+			}
+		})
+	}
+}
+
+func TestValue_ChangeDetection(t *testing.T) {
+	w := NewWorld()
+	e := SpawnEmptyEntity(w)
+	InsertValue(e, testSpeed{})
+
+	added := NewValueQuery[testSpeed](w, AddedValue[testSpeed]())
+	changed := NewValueQuery[testSpeed](w, ChangedValue[testSpeed]())
+	if added.Count() != 1 || changed.Count() != 1 {
+		t.Fatalf("new value should be added and changed")
+	}
+
+	w.Update()
+	GetValue[testSpeed](e).X = 1
+	if added.Count() != 0 || changed.Count() != 0 {
+		t.Fatalf("GetValue should not mark the value as changed")
+	}
+	GetValueMut[testSpeed](e).X = 2
+	if added.Count() != 0 || changed.Count() != 1 {
+		t.Fatalf("GetValueMut should mark the value as changed")
+	}
+}
+
+func TestValue_TagConflict(t *testing.T) {
+	w := NewWorld()
+	entity := SpawnEmptyEntity(w)
+	AddTag[testSpeed](entity)
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("using a tag type as a value component should panic")
+		}
+	}()
+	InsertValue(entity, testSpeed{})
+}
+
+type testValueChurnSystem struct {
+	System
+	a, b    IEntity
+	aliased bool
+	lost    bool
+	visible bool
+}
+
+func (s *testValueChurnSystem) Update() {
+	pa := GetValue[testSpeed](s.a)
+	pb := GetValue[testSpeed](s.b)
+
+	// 同一次运行中增加足够多的值，再移除 b 并添加新的值
+	for i := 0; i < 512; i++ {
+		InsertValue(SpawnEmptyEntity(s.GetWorld()), testSpeed{X: float64(i)})
+	}
+	RemoveValue[testSpeed](s.b)
+	c := SpawnEmptyEntity(s.GetWorld())
+	InsertValue(c, testSpeed{X: -1})
+
+	pa.X = 42
+	s.lost = GetValue[testSpeed](s.a).X != 42
+	s.aliased = pb == GetValue[testSpeed](c) || pb.X == -1
+	s.visible = GetValue[testSpeed](s.b) != nil || GetValue[testSpeed](c).X != -1
+}
+
+func TestValue_PointerStableDuringRun(t *testing.T) {
+	w := NewWorld()
+	s := &testValueChurnSystem{System: *NewSystem(w)}
+	s.b = SpawnEmptyEntity(w)
+	s.a = SpawnEmptyEntity(w)
+	InsertValue(s.b, testSpeed{X: 2})
+	InsertValue(s.a, testSpeed{X: 1})
+	w.AddUpdateSystem(s)
+
+	w.Update()
+	if s.lost {
+		t.Fatalf("writes through a pointer should survive inserts in the same run")
+	}
+	if s.aliased {
+		t.Fatalf("pointer of a removed value should not alias another entity's value")
+	}
+	if s.visible {
+		t.Fatalf("deferred inserts and removes should be visible in the same run")
+	}
+
+	// 同步点之后数组与 density 重新对齐
+	valueInfo, _ := valueInfoOf[testSpeed](w)
+	if len(valueInfo.values) != len(valueInfo.Density()) || len(valueInfo.overflow) != 0 || len(valueInfo.removed) != 0 {
+		t.Fatalf("deferred changes should be merged at the sync point")
+	}
+	if GetValue[testSpeed](s.a).X != 42 || GetValue[testSpeed](s.b) != nil || NewValueQuery[testSpeed](w).Count() != 514 {
+		t.Fatalf("values should follow their entities after merging")
+	}
+}
+
+func TestValue_ImmediateOutsideSystems(t *testing.T) {
+	// 没有系统运行时直接修改数组，不调用 Update 也不会积累延迟的增删
+	w := NewWorld()
+	for i := 0; i < 100; i++ {
+		e := SpawnEmptyEntity(w)
+		InsertValue(e, testSpeed{X: float64(i)})
+		if i%2 == 0 {
+			RemoveValue[testSpeed](e)
+		}
+	}
+	valueInfo, _ := valueInfoOf[testSpeed](w)
+	if len(valueInfo.values) != 50 || len(valueInfo.overflow) != 0 || len(valueInfo.removed) != 0 {
+		t.Fatalf("changes outside systems should be applied immediately")
+	}
+}
@@ -1,60 +1,135 @@
 package ecs
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 	"sync/atomic"
 )
 
 type EntityId uint64
 type ComponentId uint64
 
+type StorageMode int
+
+const (
+	// StorageSparseSet 组件保存在实体自身的 ComponentContainer 中（默认）
+	StorageSparseSet StorageMode = iota
+	// StorageArchetype 组件集合相同的实体保存在同一张原型表的连续列中
+	StorageArchetype
+)
+
+type WorldOption func(w *World)
+
+// WithStorageMode 设置组件的存储方式，必须在创建实体之前确定
+func WithStorageMode(mode StorageMode) WorldOption {
+	return func(w *World) {
+		w.storageMode = mode
+	}
+}
+
 type IWorld interface {
-	IncrEntId() uint64
+	IncrEntId() uint64 // Deprecated: 使用 AllocEntId
+	AllocEntId() uint64
+	IsAlive(entity IEntity) bool
 	GetResId(t reflect.Type) uint64
 	GetCompId(t reflect.Type) uint64
+	GetRelationId(t reflect.Type) uint64
 	GetCommands() *Commands
 	GetQuery() *Query
 	GetComponentMap() map[ComponentId]IComponentInfo
 	GetEntities() map[EntityId]IEntity
+	GetStorageMode() StorageMode
+}
+
+// worldInternals World 内部使用的方法，不放在 IWorld 中，外部包仍然可以实现或 mock IWorld
+type worldInternals interface {
+	IWorld
+
+	registerEntity(e IEntity)
+	ensureComponent(t reflect.Type) ComponentId
+	withRequired(e IEntity, components []IComponent) []IComponent
+	insertSparse(e IEntity, t reflect.Type, create func() IComponentInfo) (IComponentInfo, bool)
+	removeSparse(e IEntity, t reflect.Type)
+	sparseInfoOf(t reflect.Type) (IComponentInfo, bool)
+	getPoolDefaults() []PoolOption
+	insertComponent(e IEntity, componentId ComponentId, component IComponent)
+	removeComponent(e IEntity, componentId ComponentId)
+	getComponent(e IEntity, componentId ComponentId) (IComponent, bool)
+	markChanged(e IEntity, componentId ComponentId)
+	rangeComponents(e IEntity, fn func(componentId ComponentId, component IComponent))
 }
 
 type World struct {
 	IWorld
 
-	entId        uint64
-	resIdGetter  *IdentityGetter
-	compIdGetter *IdentityGetter
+	allocator        *entityAllocator
+	resIdGetter      *IdentityGetter
+	compIdGetter     *IdentityGetter
+	eventIdGetter    *IdentityGetter
+	relationIdGetter *IdentityGetter
+	registry         *Registry
+	storageMode      StorageMode
+	archetypes       *Archetypes
+	changeTick       uint64
+	ticks            *runTicks
+	activeMu         sync.Mutex
+	activeTicks      []uint64 // 正在运行的系统领取的 tick
+	workers          int
+	poolDefaults     []PoolOption
 
-	commands        *Commands
-	query           *Query
-	resourceMap     map[ComponentId]*ResourceInfo
-	componentMap    map[ComponentId]IComponentInfo
-	entities        map[EntityId]IEntity
-	destroyEntities []IEntity
-	startUpSystems  []ISystem
-	updateSystems   []ISystem
+	commands       *Commands
+	query          *Query
+	resourceMap    map[ComponentId]*ResourceInfo
+	eventMap       map[uint64]IEvents
+	eventMu        sync.RWMutex
+	componentMap   map[ComponentId]IComponentInfo
+	sparseOnly     map[ComponentId]struct{} // 标签和值组件，只记录在稀疏集中
+	hooks          map[ComponentId]*ComponentHooks
+	observers      *Observers
+	relations      *Relations
+	entities       map[EntityId]IEntity
+	startUpSystems []ISystem
+	schedule       *Schedule
 }
 
-func NewWorld() *World {
+func NewWorld(opts ...WorldOption) *World {
 	w := &World{
-		resIdGetter:  NewIdentityGetter(),
-		compIdGetter: NewIdentityGetter(),
+		allocator:        newEntityAllocator(),
+		resIdGetter:      NewIdentityGetter(),
+		compIdGetter:     NewIdentityGetter(),
+		eventIdGetter:    NewIdentityGetter(),
+		relationIdGetter: NewIdentityGetter(),
+		registry:         NewRegistry(),
 
 		resourceMap:    make(map[ComponentId]*ResourceInfo),
+		eventMap:       make(map[uint64]IEvents),
 		componentMap:   make(map[ComponentId]IComponentInfo),
+		sparseOnly:     make(map[ComponentId]struct{}),
+		hooks:          make(map[ComponentId]*ComponentHooks),
+		observers:      NewObservers(),
+		relations:      NewRelations(),
 		entities:       make(map[EntityId]IEntity),
 		startUpSystems: make([]ISystem, 0),
-		updateSystems:  make([]ISystem, 0),
+		schedule:       NewSchedule(),
+		archetypes:     NewArchetypes(),
+		changeTick:     1,
+		ticks:          newRunTicks(),
+		activeTicks:    make([]uint64, 0),
+		poolDefaults:   make([]PoolOption, 0),
+	}
+
+	for _, opt := range opts {
+		opt(w)
 	}
 
 	w.commands = NewCommands(w)
 	w.query = NewQuery(w)
 
-	return w
-}
+	RegisterComponent[*Parent](w, withReservedId(parentComponentId))
+	RegisterComponent[*Children](w, withReservedId(childrenComponentId))
 
-func (w *World) IncrEntId() uint64 {
-	return atomic.AddUint64(&w.entId, 1)
+	return w
 }
 
 func (w *World) GetResId(t reflect.Type) uint64 {
@@ -65,6 +140,10 @@ func (w *World) GetCompId(t reflect.Type) uint64 {
 	return w.compIdGetter.GetID(t)
 }
 
+func (w *World) getPoolDefaults() []PoolOption {
+	return w.poolDefaults
+}
+
 func (w *World) GetCommands() *Commands {
 	return w.commands
 }
@@ -80,41 +159,236 @@ func (w *World) GetEntities() map[EntityId]IEntity {
 	return w.entities
 }
 
+func (w *World) GetWorld() *World {
+	return w
+}
+
+func (w *World) getCommands() *Commands {
+	return w.commands
+}
+
+func (w *World) getTicks() *runTicks {
+	return w.ticks
+}
+
+func (w *World) GetStorageMode() StorageMode {
+	return w.storageMode
+}
+
+func (w *World) GetArchetypes() *Archetypes {
+	return w.archetypes
+}
+
+// registerEntity 将实体登记到世界中
+func (w *World) registerEntity(e IEntity) {
+	if _, ok := w.entities[EntityId(e.ID())]; ok {
+		return
+	}
+	w.entities[EntityId(e.ID())] = e
+	w.allocator.bind(e)
+	if w.storageMode == StorageArchetype {
+		w.archetypes.Add(e)
+	}
+}
+
+// insertComponent 为实体设置组件，已存在的同类组件会被销毁替换
+func (w *World) insertComponent(e IEntity, componentId ComponentId, component IComponent) {
+	componentInfo, ok := w.componentMap[componentId]
+	if !ok || !w.IsAlive(e) {
+		return
+	}
+
+	target, exists := w.getComponent(e, componentId)
+	if exists {
+		if target == component {
+			return
+		}
+		w.runHooks(OnReplace, e, componentId, target)
+		componentInfo.DestroyComponent(target)
+	}
+
+	if w.storageMode == StorageArchetype {
+		w.archetypes.Insert(e, componentId, component)
+	} else {
+		e.GetComponentContainer()[componentId] = component
+	}
+	componentInfo.AddEntity(e)
+
+	if exists {
+		componentInfo.SetChangedTick(EntityId(e.ID()), w.writeTick(nil))
+	} else {
+		componentInfo.SetAddedTick(EntityId(e.ID()), w.writeTick(nil))
+		w.runHooks(OnAdd, e, componentId, component)
+	}
+	w.runHooks(OnInsert, e, componentId, component)
+}
+
+// removeComponent 移除并销毁实体的组件
+func (w *World) removeComponent(e IEntity, componentId ComponentId) {
+	componentInfo, ok := w.componentMap[componentId]
+	if !ok {
+		return
+	}
+
+	target, exists := w.getComponent(e, componentId)
+	if !exists {
+		return
+	}
+
+	w.runHooks(OnReplace, e, componentId, target)
+	w.runHooks(OnRemove, e, componentId, target)
+	componentInfo.DestroyComponent(target)
+	if w.storageMode == StorageArchetype {
+		w.archetypes.Delete(e, componentId)
+	} else {
+		delete(e.GetComponentContainer(), componentId)
+	}
+	componentInfo.RemoveEntity(e)
+}
+
+func (w *World) getComponent(e IEntity, componentId ComponentId) (IComponent, bool) {
+	// 失效的句柄不能访问复用了同一槽位的新实体的组件
+	if !w.IsAlive(e) {
+		return nil, false
+	}
+	if w.storageMode == StorageArchetype {
+		return w.archetypes.Get(e, componentId)
+	}
+	component, ok := e.GetComponentContainer()[componentId]
+	return component, ok
+}
+
+func (w *World) rangeComponents(e IEntity, fn func(componentId ComponentId, component IComponent)) {
+	if w.storageMode == StorageArchetype {
+		w.archetypes.Range(e, fn)
+		return
+	}
+	for componentId, component := range e.GetComponentContainer() {
+		fn(componentId, component)
+	}
+}
+
 func (w *World) AddStartUpSystem(startUpSystem ISystem) *World {
 	w.startUpSystems = append(w.startUpSystems, startUpSystem)
 	return w
 }
 
-func (w *World) AddUpdateSystem(updateSystem ISystem) *World {
-	w.updateSystems = append(w.updateSystems, updateSystem)
+// AddUpdateSystem 添加到 Update 阶段
+func (w *World) AddUpdateSystem(updateSystem ISystem, opts ...SystemOption) *World {
+	return w.AddSystem(StageUpdate, updateSystem, opts...)
+}
+
+// AddSystem 添加系统到指定阶段，可以通过 Label / Before / After 声明顺序
+func (w *World) AddSystem(stage Stage, system ISystem, opts ...SystemOption) *World {
+	w.schedule.AddSystem(stage, system, opts...)
 	return w
 }
 
+func (w *World) GetSchedule() *Schedule {
+	return w.schedule
+}
+
+// BuildSchedule 对所有阶段的系统排序，存在循环依赖时返回错误
+func (w *World) BuildSchedule() error {
+	return w.schedule.Build()
+}
+
 func (w *World) destroy(entity IEntity) {
-	for componentId, component := range entity.GetComponentContainer() {
+	if !w.IsAlive(entity) {
+		return
+	}
+
+	// 先触发所有组件的移除回调，回调中仍然可以访问实体的其他组件
+	type attached struct {
+		componentId ComponentId
+		component   IComponent
+	}
+	components := make([]attached, 0)
+	w.rangeComponents(entity, func(componentId ComponentId, component IComponent) {
+		components = append(components, attached{componentId, component})
+	})
+	for _, c := range components {
+		w.runHooks(OnReplace, entity, c.componentId, c.component)
+		w.runHooks(OnRemove, entity, c.componentId, c.component)
+	}
+
+	w.rangeComponents(entity, func(componentId ComponentId, component IComponent) {
 		componentInfo := w.componentMap[componentId]
 		componentInfo.DestroyComponent(component)
 		componentInfo.RemoveEntity(entity)
+	})
+	if w.storageMode != StorageArchetype {
+		clear(entity.GetComponentContainer())
 	}
+	w.removeSparseOnly(entity)
+	w.relations.removeEntity(w, EntityId(entity.ID()))
+	w.archetypes.Remove(entity)
 	delete(w.entities, EntityId(entity.ID()))
+	w.allocator.free(EntityId(entity.ID()))
 }
 
 func (w *World) Startup() {
 	for _, system := range w.startUpSystems {
-		system.StartUp()
+		w.runWithTicks(systemTicks(system), system.StartUp)
 	}
+	w.applyCommands(w.startUpSystems...)
 }
 
+// Update 按阶段顺序执行所有系统，调度构建失败时会 panic，可以提前调用 BuildSchedule 检查
 func (w *World) Update() {
-	for _, system := range w.updateSystems {
-		system.Update()
+	if err := w.schedule.Build(); err != nil {
+		panic(err)
+	}
+
+	w.ticks.thisRun = w.ChangeTick()
+
+	// 每个阶段前后都是同步点，延迟的命令在这里统一执行
+	w.applyCommands()
+	for _, stage := range w.schedule.Stages() {
+		w.runStage(stage)
+		w.applyCommands(w.schedule.Systems(stage)...)
+	}
+
+	// 交换所有已注册事件的缓冲，本帧发送的事件在下一帧仍然可读
+	w.updateEvents()
+
+	// World 上下文的查询以本次 Update 结束为基准
+	w.ticks.lastRun = atomic.AddUint64(&w.changeTick, 1) - 1
+}
+
+// applyCommands 在同步点执行延迟的命令，先领取新的 tick，命令的修改对所有系统都是新的
+// 每个系统拥有独立的命令缓冲，按系统在调度中的顺序合并，结果与并行执行时的协程调度无关
+func (w *World) applyCommands(systems ...ISystem) {
+	atomic.AddUint64(&w.changeTick, 1)
+	for _, system := range systems {
+		if buffer := systemCommands(system); buffer != nil && buffer != w.commands {
+			w.commands.Append(buffer)
+		}
 	}
+	w.flushValues()
+	w.commands.Execute()
 }
 
 func (w *World) Shutdown() {
 	w.resourceMap = make(map[ComponentId]*ResourceInfo)
+	w.eventMap = make(map[uint64]IEvents)
 	w.componentMap = make(map[ComponentId]IComponentInfo)
+	w.sparseOnly = make(map[ComponentId]struct{})
+	w.hooks = make(map[ComponentId]*ComponentHooks)
+	w.observers = NewObservers()
+	w.relations = NewRelations()
 	w.entities = make(map[EntityId]IEntity)
+	w.allocator = newEntityAllocator()
+	w.archetypes = NewArchetypes()
 	w.startUpSystems = make([]ISystem, 0)
-	w.updateSystems = make([]ISystem, 0)
+	w.schedule = NewSchedule()
+}
+
+// internalsOf 实体和组件所属的 World，IWorld 的其他实现不能用来创建实体和组件
+func internalsOf(w IWorld) worldInternals {
+	internals, ok := w.(worldInternals)
+	if !ok {
+		panic(fmt.Errorf("ecs: %T is not a world created by NewWorld", w))
+	}
+	return internals
 }
@@ -76,6 +76,18 @@ func (s *SparseSet[T]) Contains(t T) bool {
 	return p < len(s.sparse) && o < len(s.sparse[p]) && s.sparse[p][o] > EMPTY
 }
 
+// Index 返回元素在 density 中的下标
+func (s *SparseSet[T]) Index(t T) (int, bool) {
+	if !s.Contains(t) {
+		return 0, false
+	}
+	return s.getDensityIndex(t), true
+}
+
+func (s *SparseSet[T]) Len() int {
+	return len(s.density)
+}
+
 func (s *SparseSet[T]) Clear() {
 	s.density = make([]T, 0)
 	s.sparse = make([][]int32, 0)
@@ -55,3 +55,19 @@ func TestSparseSet_DuplicateAdd(t *testing.T) {
 		t.Errorf("Duplicate Add failed: expected 1, got %d", count)
 	}
 }
+
+func TestSparseSet_Index(t *testing.T) {
+	s := NewSparseSet[uint64](4)
+	s.Add(7)
+	s.Add(9)
+	s.Add(11)
+
+	s.Remove(7)
+	// 最后一个元素被交换到了被删除的位置
+	if i, ok := s.Index(11); !ok || i != 0 {
+		t.Errorf("Index failed: expected 11 at 0, got %d %v", i, ok)
+	}
+	if _, ok := s.Index(7); ok {
+		t.Errorf("Index failed: 7 should not be contained")
+	}
+}
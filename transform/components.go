@@ -0,0 +1,43 @@
+package transform
+
+import "github.com/INT-Game/go-ecs/ecs"
+
+// LocalTransform2D 相对于父节点的变换，没有父节点时相对于世界
+type LocalTransform2D struct {
+	ecs.Component
+	Transform2D
+}
+
+func (t *LocalTransform2D) Init() {
+	t.Transform2D = IdentityTransform2D()
+}
+
+// GlobalTransform2D 世界空间中的变换，由传播系统计算，不要直接修改
+type GlobalTransform2D struct {
+	ecs.Component
+	Transform2D
+}
+
+func (t *GlobalTransform2D) Init() {
+	t.Transform2D = IdentityTransform2D()
+}
+
+// LocalTransform3D 相对于父节点的变换，没有父节点时相对于世界
+type LocalTransform3D struct {
+	ecs.Component
+	Transform3D
+}
+
+func (t *LocalTransform3D) Init() {
+	t.Transform3D = IdentityTransform3D()
+}
+
+// GlobalTransform3D 世界空间中的变换，由传播系统计算，不要直接修改
+type GlobalTransform3D struct {
+	ecs.Component
+	Transform3D
+}
+
+func (t *GlobalTransform3D) Init() {
+	t.Transform3D = IdentityTransform3D()
+}
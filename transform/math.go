@@ -0,0 +1,123 @@
+package transform
+
+import "math"
+
+type Vec2 struct {
+	X, Y float64
+}
+
+func (v Vec2) Add(o Vec2) Vec2 {
+	return Vec2{v.X + o.X, v.Y + o.Y}
+}
+
+// Mul 逐分量相乘
+func (v Vec2) Mul(o Vec2) Vec2 {
+	return Vec2{v.X * o.X, v.Y * o.Y}
+}
+
+// Rotate 绕原点逆时针旋转，单位为弧度
+func (v Vec2) Rotate(angle float64) Vec2 {
+	sin, cos := math.Sincos(angle)
+	return Vec2{v.X*cos - v.Y*sin, v.X*sin + v.Y*cos}
+}
+
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+func (v Vec3) Add(o Vec3) Vec3 {
+	return Vec3{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+// Mul 逐分量相乘
+func (v Vec3) Mul(o Vec3) Vec3 {
+	return Vec3{v.X * o.X, v.Y * o.Y, v.Z * o.Z}
+}
+
+func (v Vec3) Scale(s float64) Vec3 {
+	return Vec3{v.X * s, v.Y * s, v.Z * s}
+}
+
+func (v Vec3) Cross(o Vec3) Vec3 {
+	return Vec3{v.Y*o.Z - v.Z*o.Y, v.Z*o.X - v.X*o.Z, v.X*o.Y - v.Y*o.X}
+}
+
+// Quat 单位四元数表示的旋转
+type Quat struct {
+	X, Y, Z, W float64
+}
+
+func IdentityQuat() Quat {
+	return Quat{W: 1}
+}
+
+// QuatFromAxisAngle 绕单位向量 axis 旋转 angle 弧度
+func QuatFromAxisAngle(axis Vec3, angle float64) Quat {
+	sin, cos := math.Sincos(angle / 2)
+	return Quat{axis.X * sin, axis.Y * sin, axis.Z * sin, cos}
+}
+
+// Mul 先应用 o 再应用 q 的旋转
+func (q Quat) Mul(o Quat) Quat {
+	return Quat{
+		X: q.W*o.X + q.X*o.W + q.Y*o.Z - q.Z*o.Y,
+		Y: q.W*o.Y - q.X*o.Z + q.Y*o.W + q.Z*o.X,
+		Z: q.W*o.Z + q.X*o.Y - q.Y*o.X + q.Z*o.W,
+		W: q.W*o.W - q.X*o.X - q.Y*o.Y - q.Z*o.Z,
+	}
+}
+
+func (q Quat) Rotate(v Vec3) Vec3 {
+	u := Vec3{q.X, q.Y, q.Z}
+	t := u.Cross(v).Scale(2)
+	return v.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
+
+// Transform2D 二维的平移、旋转（弧度）和缩放
+type Transform2D struct {
+	Translation Vec2
+	Rotation    float64
+	Scale       Vec2
+}
+
+func IdentityTransform2D() Transform2D {
+	return Transform2D{Scale: Vec2{1, 1}}
+}
+
+// Mul 组合父子变换，结果是子节点在父节点空间之外的变换
+func (t Transform2D) Mul(child Transform2D) Transform2D {
+	return Transform2D{
+		Translation: t.TransformPoint(child.Translation),
+		Rotation:    t.Rotation + child.Rotation,
+		Scale:       t.Scale.Mul(child.Scale),
+	}
+}
+
+func (t Transform2D) TransformPoint(p Vec2) Vec2 {
+	return p.Mul(t.Scale).Rotate(t.Rotation).Add(t.Translation)
+}
+
+// Transform3D 三维的平移、旋转和缩放
+// 父节点存在非均匀缩放且子节点带旋转时，组合结果无法精确表示切变，与常见引擎的做法一致
+type Transform3D struct {
+	Translation Vec3
+	Rotation    Quat
+	Scale       Vec3
+}
+
+func IdentityTransform3D() Transform3D {
+	return Transform3D{Rotation: IdentityQuat(), Scale: Vec3{1, 1, 1}}
+}
+
+// Mul 组合父子变换，结果是子节点在父节点空间之外的变换
+func (t Transform3D) Mul(child Transform3D) Transform3D {
+	return Transform3D{
+		Translation: t.TransformPoint(child.Translation),
+		Rotation:    t.Rotation.Mul(child.Rotation),
+		Scale:       t.Scale.Mul(child.Scale),
+	}
+}
+
+func (t Transform3D) TransformPoint(p Vec3) Vec3 {
+	return t.Rotation.Rotate(p.Mul(t.Scale)).Add(t.Translation)
+}
@@ -0,0 +1,111 @@
+package transform
+
+import "github.com/INT-Game/go-ecs/ecs"
+
+// PropagateLabel 传播系统的标签，需要读取 GlobalTransform 的系统可以声明 After(PropagateLabel)
+const PropagateLabel ecs.SystemLabel = "transform.propagate"
+
+// PropagateSystem 沿着父子层级从根节点向下计算 GlobalTransform
+// 实体的 LocalTransform 被修改、GlobalTransform 刚被添加、父节点发生变化时标记为脏，
+// 脏节点的整棵子树重新计算，其余节点保持不变；缺少变换组件的子节点及其子树不参与传播
+type PropagateSystem[L ecs.IComponent, G ecs.IComponent] struct {
+	ecs.System
+	roots      *ecs.Query2[L, G]
+	transforms *ecs.Query2[L, G]
+	changed    *ecs.Query1[L]
+	added      *ecs.Query1[G]
+	reparented *ecs.Query1[*ecs.Parent]
+	orphans    map[ecs.EntityId]struct{}
+	root       func(local L, global G)
+	child      func(parent G, local L, global G)
+}
+
+type PropagateSystem2D = PropagateSystem[*LocalTransform2D, *GlobalTransform2D]
+type PropagateSystem3D = PropagateSystem[*LocalTransform3D, *GlobalTransform3D]
+
+func newPropagateSystem[L ecs.IComponent, G ecs.IComponent](w *ecs.World, root func(local L, global G), child func(parent G, local L, global G)) *PropagateSystem[L, G] {
+	s := &PropagateSystem[L, G]{
+		System:  *ecs.NewSystem(w),
+		orphans: make(map[ecs.EntityId]struct{}),
+		root:    root,
+		child:   child,
+	}
+	var local L
+	var global G
+	s.Reads(local, &ecs.Parent{}, &ecs.Children{}).Writes(global)
+
+	s.roots = ecs.NewQuery2[L, G](&s.System, ecs.Without(&ecs.Parent{}))
+	s.transforms = ecs.NewQuery2[L, G](&s.System)
+	s.changed = ecs.NewQuery1[L](&s.System, ecs.Changed[L]())
+	s.added = ecs.NewQuery1[G](&s.System, ecs.Added[G]())
+	s.reparented = ecs.NewQuery1[*ecs.Parent](&s.System, ecs.Changed[*ecs.Parent]())
+
+	// 移除 Parent 无法通过变更检测发现，成为根节点的实体需要重新计算
+	w.Observe(ecs.OnRemove, func(trigger *ecs.Trigger) {
+		s.orphans[ecs.EntityId(trigger.Entity.ID())] = struct{}{}
+	}, ecs.ObserveComponents(&ecs.Parent{}))
+	return s
+}
+
+func NewPropagateSystem2D(w *ecs.World) *PropagateSystem2D {
+	return newPropagateSystem(w,
+		func(local *LocalTransform2D, global *GlobalTransform2D) {
+			global.Transform2D = local.Transform2D
+		},
+		func(parent *GlobalTransform2D, local *LocalTransform2D, global *GlobalTransform2D) {
+			global.Transform2D = parent.Mul(local.Transform2D)
+		})
+}
+
+func NewPropagateSystem3D(w *ecs.World) *PropagateSystem3D {
+	return newPropagateSystem(w,
+		func(local *LocalTransform3D, global *GlobalTransform3D) {
+			global.Transform3D = local.Transform3D
+		},
+		func(parent *GlobalTransform3D, local *LocalTransform3D, global *GlobalTransform3D) {
+			global.Transform3D = parent.Mul(local.Transform3D)
+		})
+}
+
+func (s *PropagateSystem[L, G]) dirty(entity ecs.IEntity) bool {
+	if _, ok := s.orphans[ecs.EntityId(entity.ID())]; ok {
+		return true
+	}
+	return s.changed.Matches(entity) || s.added.Matches(entity) || s.reparented.Matches(entity)
+}
+
+func (s *PropagateSystem[L, G]) Update() {
+	s.roots.ForEach(func(entity ecs.IEntity, local L, global G) {
+		dirty := s.dirty(entity)
+		if dirty {
+			s.root(local, ecs.GetComponentMut[G](entity))
+		}
+		s.propagate(entity, global, dirty)
+	})
+	s.orphans = make(map[ecs.EntityId]struct{})
+}
+
+func (s *PropagateSystem[L, G]) propagate(parent ecs.IEntity, parentGlobal G, parentDirty bool) {
+	for _, child := range ecs.GetChildren(parent) {
+		local, global, ok := s.transforms.Get(child)
+		if !ok {
+			continue
+		}
+		dirty := parentDirty || s.dirty(child)
+		if dirty {
+			s.child(parentGlobal, local, ecs.GetComponentMut[G](child))
+		}
+		s.propagate(child, global, dirty)
+	}
+}
+
+// AddSystems 注册变换组件，并在 PostUpdate 阶段添加 2D 和 3D 的传播系统
+func AddSystems(w *ecs.World) {
+	ecs.RegisterComponent[*LocalTransform2D](w)
+	ecs.RegisterComponent[*GlobalTransform2D](w)
+	ecs.RegisterComponent[*LocalTransform3D](w)
+	ecs.RegisterComponent[*GlobalTransform3D](w)
+
+	w.AddSystem(ecs.StagePostUpdate, NewPropagateSystem2D(w), ecs.Label(PropagateLabel))
+	w.AddSystem(ecs.StagePostUpdate, NewPropagateSystem3D(w), ecs.Label(PropagateLabel))
+}
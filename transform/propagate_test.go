@@ -0,0 +1,90 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/INT-Game/go-ecs/ecs"
+)
+
+func spawn2D(w *ecs.World, x, y float64) ecs.IEntity {
+	local := ecs.SpawnComponent[*LocalTransform2D](w)
+	local.Translation = Vec2{x, y}
+	return ecs.SpawnEmptyEntity(w, local, ecs.SpawnComponent[*GlobalTransform2D](w))
+}
+
+func global2D(entity ecs.IEntity) Vec2 {
+	return ecs.GetComponent[*GlobalTransform2D](entity).Translation
+}
+
+func near(a, b Vec2) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestPropagate2D(t *testing.T) {
+	w := ecs.NewWorld()
+	AddSystems(w)
+
+	root := spawn2D(w, 10, 0)
+	child := spawn2D(w, 1, 0)
+	leaf := spawn2D(w, 0, 1)
+	w.GetCommands().AddChild(root, child).AddChild(child, leaf)
+	w.Update()
+
+	if got := global2D(leaf); !near(got, Vec2{11, 1}) {
+		t.Fatalf("expected leaf at (11, 1), got %v", got)
+	}
+
+	// 旋转根节点后整棵子树重新计算
+	ecs.GetComponentMut[*LocalTransform2D](root).Rotation = math.Pi / 2
+	w.Update()
+	if got := global2D(leaf); !near(got, Vec2{9, 1}) {
+		t.Fatalf("expected leaf at (9, 1), got %v", got)
+	}
+
+	// 没有变化的子树不会重新计算
+	ecs.GetComponent[*GlobalTransform2D](leaf).Translation = Vec2{-1, -1}
+	w.Update()
+	if got := global2D(leaf); !near(got, Vec2{-1, -1}) {
+		t.Fatalf("clean subtree should not be recomputed, got %v", got)
+	}
+
+	ecs.GetComponentMut[*LocalTransform2D](leaf).Translation = Vec2{0, 2}
+	w.Update()
+	if got := global2D(leaf); !near(got, Vec2{8, 1}) {
+		t.Fatalf("expected leaf at (8, 1), got %v", got)
+	}
+
+	// 移除父节点后成为根节点
+	w.GetCommands().RemoveParent(leaf)
+	w.Update()
+	if got := global2D(leaf); !near(got, Vec2{0, 2}) {
+		t.Fatalf("orphaned leaf should use its local transform, got %v", got)
+	}
+}
+
+func TestPropagate3D(t *testing.T) {
+	w := ecs.NewWorld(ecs.WithStorageMode(ecs.StorageArchetype))
+	AddSystems(w)
+
+	rootLocal := ecs.SpawnComponent[*LocalTransform3D](w)
+	rootLocal.Translation = Vec3{0, 0, 5}
+	rootLocal.Rotation = QuatFromAxisAngle(Vec3{0, 0, 1}, math.Pi/2)
+	rootLocal.Scale = Vec3{2, 2, 2}
+	root := ecs.SpawnEmptyEntity(w, rootLocal, ecs.SpawnComponent[*GlobalTransform3D](w))
+
+	childLocal := ecs.SpawnComponent[*LocalTransform3D](w)
+	childLocal.Translation = Vec3{1, 0, 0}
+	child := ecs.SpawnEmptyEntity(w, childLocal, ecs.SpawnComponent[*GlobalTransform3D](w))
+	w.GetCommands().AddChild(root, child)
+	w.Update()
+
+	global := ecs.GetComponent[*GlobalTransform3D](child)
+	got := global.Translation
+	if math.Abs(got.X) > 1e-9 || math.Abs(got.Y-2) > 1e-9 || math.Abs(got.Z-5) > 1e-9 {
+		t.Fatalf("expected child at (0, 2, 5), got %v", got)
+	}
+	if global.Scale != (Vec3{2, 2, 2}) {
+		t.Fatalf("expected inherited scale, got %v", global.Scale)
+	}
+}